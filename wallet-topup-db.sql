@@ -29,3 +29,501 @@ CREATE TABLE IF NOT EXISTS public.transactions (
 
 ALTER TABLE IF EXISTS public.transactions
     OWNER to postgres;
+
+
+-- TRANSFERS TABLE
+CREATE TABLE IF NOT EXISTS public.transfers (
+    transfer_id uuid NOT NULL,
+    from_user_id bigint NOT NULL,
+    to_user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    note text COLLATE pg_catalog."default",
+    status text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT transfers_pkey PRIMARY KEY (transfer_id),
+    CONSTRAINT transfers_from_user_id_fkey FOREIGN KEY (from_user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT transfers_to_user_id_fkey FOREIGN KEY (to_user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS transfers_from_user_id_created_at_idx
+    ON public.transfers (from_user_id, created_at);
+
+ALTER TABLE IF EXISTS public.transfers
+    OWNER to postgres;
+
+
+-- LEDGER ENTRIES TABLE
+CREATE TABLE IF NOT EXISTS public.ledger_entries (
+    entry_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    type text COLLATE pg_catalog."default" NOT NULL,
+    reference text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT ledger_entries_pkey PRIMARY KEY (entry_id),
+    CONSTRAINT ledger_entries_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_reference_idx
+    ON public.ledger_entries (reference);
+
+ALTER TABLE IF EXISTS public.ledger_entries
+    OWNER to postgres;
+
+
+-- WITHDRAWALS TABLE
+CREATE TABLE IF NOT EXISTS public.withdrawals (
+    withdrawal_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    bank_account text COLLATE pg_catalog."default" NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    hold_id uuid,
+    payout_reference text COLLATE pg_catalog."default",
+    failure_reason text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT withdrawals_pkey PRIMARY KEY (withdrawal_id),
+    CONSTRAINT withdrawals_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT withdrawals_status_check CHECK (status = ANY (ARRAY['requested'::text, 'held'::text, 'submitted'::text, 'paid'::text, 'failed'::text]))
+);
+
+CREATE INDEX IF NOT EXISTS withdrawals_user_id_created_at_idx
+    ON public.withdrawals (user_id, created_at);
+
+ALTER TABLE IF EXISTS public.withdrawals
+    OWNER to postgres;
+
+
+-- HOLDS TABLE
+CREATE TABLE IF NOT EXISTS public.holds (
+    hold_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    reason text COLLATE pg_catalog."default",
+    status text COLLATE pg_catalog."default" NOT NULL,
+    expires_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT holds_pkey PRIMARY KEY (hold_id),
+    CONSTRAINT holds_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT holds_status_check CHECK (status = ANY (ARRAY['active'::text, 'captured'::text, 'released'::text, 'expired'::text]))
+);
+
+CREATE INDEX IF NOT EXISTS holds_user_id_status_idx
+    ON public.holds (user_id, status);
+
+ALTER TABLE IF EXISTS public.holds
+    OWNER to postgres;
+
+
+-- WALLET BALANCES NEVER GO NEGATIVE
+ALTER TABLE IF EXISTS public.users
+    ADD CONSTRAINT users_balance_non_negative CHECK (balance >= 0);
+
+
+-- MERCHANTS TABLE
+CREATE TABLE IF NOT EXISTS public.merchants (
+    merchant_id bigserial NOT NULL,
+    name text COLLATE pg_catalog."default" NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL DEFAULT 'active',
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT merchants_pkey PRIMARY KEY (merchant_id)
+);
+
+ALTER TABLE IF EXISTS public.merchants
+    OWNER to postgres;
+
+
+-- PAYMENTS TABLE
+CREATE TABLE IF NOT EXISTS public.payments (
+    payment_id uuid NOT NULL,
+    merchant_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    refunded_amount numeric(12,2) NOT NULL DEFAULT 0,
+    order_reference text COLLATE pg_catalog."default" NOT NULL,
+    description text COLLATE pg_catalog."default",
+    status text COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT payments_pkey PRIMARY KEY (payment_id),
+    CONSTRAINT payments_merchant_reference_key UNIQUE (merchant_id, order_reference),
+    CONSTRAINT payments_merchant_id_fkey FOREIGN KEY (merchant_id)
+        REFERENCES public.merchants (merchant_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT payments_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT payments_refund_check CHECK (refunded_amount >= 0 AND refunded_amount <= amount)
+);
+
+ALTER TABLE IF EXISTS public.payments
+    OWNER to postgres;
+
+
+-- TOP-UP SCHEDULES TABLE
+CREATE TABLE IF NOT EXISTS public.top_up_schedules (
+    schedule_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    payment_method text COLLATE pg_catalog."default",
+    payment_token text COLLATE pg_catalog."default" NOT NULL,
+    cron_expr text COLLATE pg_catalog."default" NOT NULL,
+    failure_policy text COLLATE pg_catalog."default" NOT NULL,
+    max_retries integer NOT NULL DEFAULT 0,
+    retry_count integer NOT NULL DEFAULT 0,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    next_run_at timestamp with time zone NOT NULL,
+    last_run_at timestamp with time zone,
+    pending_transaction_id text COLLATE pg_catalog."default" NOT NULL DEFAULT ''::text,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT top_up_schedules_pkey PRIMARY KEY (schedule_id),
+    CONSTRAINT top_up_schedules_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT top_up_schedules_status_check CHECK (status = ANY (ARRAY['active'::text, 'paused'::text])),
+    CONSTRAINT top_up_schedules_failure_policy_check CHECK (failure_policy = ANY (ARRAY['retry'::text, 'skip'::text]))
+);
+
+CREATE INDEX IF NOT EXISTS top_up_schedules_status_next_run_at_idx
+    ON public.top_up_schedules (status, next_run_at);
+
+ALTER TABLE IF EXISTS public.top_up_schedules
+    OWNER to postgres;
+
+
+-- SCHEDULE RUNS TABLE
+CREATE TABLE IF NOT EXISTS public.schedule_runs (
+    run_id uuid NOT NULL,
+    schedule_id uuid NOT NULL,
+    attempt integer NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    transaction_id uuid,
+    error text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT schedule_runs_pkey PRIMARY KEY (run_id),
+    CONSTRAINT schedule_runs_schedule_id_fkey FOREIGN KEY (schedule_id)
+        REFERENCES public.top_up_schedules (schedule_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS schedule_runs_schedule_id_created_at_idx
+    ON public.schedule_runs (schedule_id, created_at);
+
+ALTER TABLE IF EXISTS public.schedule_runs
+    OWNER to postgres;
+
+
+-- AUTO TOP-UP CONFIGS TABLE
+CREATE TABLE IF NOT EXISTS public.auto_top_up_configs (
+    user_id bigint NOT NULL,
+    threshold numeric(12,2) NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    daily_limit numeric(12,2) NOT NULL,
+    payment_method text COLLATE pg_catalog."default",
+    payment_token text COLLATE pg_catalog."default" NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT auto_top_up_configs_pkey PRIMARY KEY (user_id),
+    CONSTRAINT auto_top_up_configs_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE CASCADE
+);
+
+ALTER TABLE IF EXISTS public.auto_top_up_configs
+    OWNER to postgres;
+
+
+-- AUTO TOP-UPS TABLE
+CREATE TABLE IF NOT EXISTS public.auto_top_ups (
+    auto_top_up_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    transaction_id uuid,
+    error text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT auto_top_ups_pkey PRIMARY KEY (auto_top_up_id),
+    CONSTRAINT auto_top_ups_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS auto_top_ups_user_id_created_at_idx
+    ON public.auto_top_ups (user_id, created_at);
+
+ALTER TABLE IF EXISTS public.auto_top_ups
+    OWNER to postgres;
+
+
+-- BATCHES TABLE
+CREATE TABLE IF NOT EXISTS public.batches (
+    batch_id uuid NOT NULL,
+    reference text COLLATE pg_catalog."default" NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    total_rows integer NOT NULL,
+    succeeded_rows integer NOT NULL DEFAULT 0,
+    failed_rows integer NOT NULL DEFAULT 0,
+    total_amount numeric(14,2) NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    completed_at timestamp with time zone,
+    CONSTRAINT batches_pkey PRIMARY KEY (batch_id),
+    CONSTRAINT batches_reference_key UNIQUE (reference)
+);
+
+ALTER TABLE IF EXISTS public.batches
+    OWNER to postgres;
+
+
+-- BATCH ROWS TABLE
+CREATE TABLE IF NOT EXISTS public.batch_rows (
+    batch_id uuid NOT NULL,
+    row_number integer NOT NULL,
+    user_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    reference text COLLATE pg_catalog."default" NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    error text COLLATE pg_catalog."default",
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT batch_rows_pkey PRIMARY KEY (batch_id, row_number),
+    CONSTRAINT batch_rows_batch_id_fkey FOREIGN KEY (batch_id)
+        REFERENCES public.batches (batch_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE CASCADE,
+    CONSTRAINT batch_rows_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT batch_rows_status_check CHECK (status = ANY (ARRAY['pending'::text, 'credited'::text, 'failed'::text]))
+);
+
+ALTER TABLE IF EXISTS public.batch_rows
+    OWNER to postgres;
+
+
+-- ADJUSTMENTS TABLE
+CREATE TABLE IF NOT EXISTS public.adjustments (
+    adjustment_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    direction text COLLATE pg_catalog."default" NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    reason_code text COLLATE pg_catalog."default" NOT NULL,
+    evidence text COLLATE pg_catalog."default" NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL,
+    proposed_by text COLLATE pg_catalog."default" NOT NULL,
+    reviewed_by text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    review_note text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    reviewed_at timestamp with time zone,
+    CONSTRAINT adjustments_pkey PRIMARY KEY (adjustment_id),
+    CONSTRAINT adjustments_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT adjustments_direction_check CHECK (direction = ANY (ARRAY['credit'::text, 'debit'::text])),
+    CONSTRAINT adjustments_status_check CHECK (status = ANY (ARRAY['pending'::text, 'approved'::text, 'rejected'::text])),
+    CONSTRAINT adjustments_four_eyes_check CHECK (reviewed_by = '' OR reviewed_by <> proposed_by)
+);
+
+ALTER TABLE IF EXISTS public.adjustments
+    OWNER to postgres;
+
+
+-- AUDIT LOGS TABLE
+CREATE TABLE IF NOT EXISTS public.audit_logs (
+    audit_id uuid NOT NULL,
+    actor text COLLATE pg_catalog."default" NOT NULL,
+    action text COLLATE pg_catalog."default" NOT NULL,
+    entity_type text COLLATE pg_catalog."default" NOT NULL,
+    entity_id text COLLATE pg_catalog."default" NOT NULL,
+    details jsonb NOT NULL DEFAULT '{}'::jsonb,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT audit_logs_pkey PRIMARY KEY (audit_id)
+);
+
+ALTER TABLE IF EXISTS public.audit_logs
+    OWNER to postgres;
+
+CREATE INDEX IF NOT EXISTS audit_logs_entity_idx
+    ON public.audit_logs (entity_type, entity_id, created_at);
+
+
+-- WALLET STATUS
+ALTER TABLE IF EXISTS public.users
+    ADD COLUMN IF NOT EXISTS status text COLLATE pg_catalog."default" NOT NULL DEFAULT 'active',
+    ADD CONSTRAINT users_status_check CHECK (status = ANY (ARRAY['active'::text, 'frozen'::text, 'suspended'::text, 'closed'::text]));
+
+
+-- WALLET STATUS CHANGES TABLE
+CREATE TABLE IF NOT EXISTS public.wallet_status_changes (
+    change_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    from_status text COLLATE pg_catalog."default" NOT NULL,
+    to_status text COLLATE pg_catalog."default" NOT NULL,
+    reason text COLLATE pg_catalog."default" NOT NULL,
+    actor text COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT wallet_status_changes_pkey PRIMARY KEY (change_id),
+    CONSTRAINT wallet_status_changes_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION
+);
+
+ALTER TABLE IF EXISTS public.wallet_status_changes
+    OWNER to postgres;
+
+
+-- KYC TIERS
+-- Wallets that existed before tiers are treated as verified so they keep
+-- their limits; only users created afterwards start at basic.
+ALTER TABLE IF EXISTS public.users
+    ADD COLUMN IF NOT EXISTS kyc_tier text COLLATE pg_catalog."default" NOT NULL DEFAULT 'verified',
+    ADD CONSTRAINT users_kyc_tier_check CHECK (kyc_tier = ANY (ARRAY['basic'::text, 'verified'::text, 'premium'::text]));
+
+ALTER TABLE IF EXISTS public.users
+    ALTER COLUMN kyc_tier SET DEFAULT 'basic';
+
+-- Existing transactions were verified 15 minutes (VerifyTTL) before they
+-- expired, so that is their creation time for the daily limit.
+ALTER TABLE IF EXISTS public.transactions
+    ADD COLUMN IF NOT EXISTS created_at timestamp with time zone;
+
+UPDATE public.transactions
+    SET created_at = expires_at - interval '15 minutes'
+    WHERE created_at IS NULL;
+
+ALTER TABLE IF EXISTS public.transactions
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS transactions_user_created_idx
+    ON public.transactions (user_id, created_at);
+
+
+-- USER PROFILES
+ALTER TABLE IF EXISTS public.users
+    ADD COLUMN IF NOT EXISTS external_ref text COLLATE pg_catalog."default",
+    ADD COLUMN IF NOT EXISTS full_name text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS email text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS phone text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_at timestamp with time zone NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    ALTER COLUMN balance SET DEFAULT 0,
+    ADD CONSTRAINT users_external_ref_key UNIQUE (external_ref);
+
+
+-- TRANSACTION EXTENSIONS
+ALTER TABLE IF EXISTS public.transactions
+    ADD COLUMN IF NOT EXISTS extensions integer NOT NULL DEFAULT 0;
+
+
+-- CREDENTIALS TABLE
+CREATE TABLE IF NOT EXISTS public.credentials (
+    username text COLLATE pg_catalog."default" NOT NULL,
+    password_hash text COLLATE pg_catalog."default" NOT NULL,
+    user_id bigint,
+    roles text COLLATE pg_catalog."default" NOT NULL,
+    disabled boolean NOT NULL DEFAULT false,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT credentials_pkey PRIMARY KEY (username),
+    CONSTRAINT credentials_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION
+);
+
+ALTER TABLE IF EXISTS public.credentials
+    OWNER to postgres;
+
+
+-- MERCHANT API KEYS
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    key_id text COLLATE pg_catalog."default" NOT NULL,
+    merchant_id bigint NOT NULL,
+    secret_hash text COLLATE pg_catalog."default" NOT NULL,
+    status text COLLATE pg_catalog."default" NOT NULL DEFAULT 'active',
+    created_by text COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    expires_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    CONSTRAINT api_keys_pkey PRIMARY KEY (key_id),
+    CONSTRAINT api_keys_merchant_id_fkey FOREIGN KEY (merchant_id)
+        REFERENCES public.merchants (merchant_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION,
+    CONSTRAINT api_keys_status_check CHECK (status = ANY (ARRAY['active'::text, 'revoked'::text]))
+);
+
+ALTER TABLE IF EXISTS public.api_keys
+    OWNER to postgres;
+
+CREATE INDEX IF NOT EXISTS api_keys_merchant_idx
+    ON public.api_keys (merchant_id);
+
+
+-- OAUTH CLIENTS
+CREATE TABLE IF NOT EXISTS public.oauth_clients (
+    client_id text COLLATE pg_catalog."default" NOT NULL,
+    name text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    secret_hash text COLLATE pg_catalog."default" NOT NULL,
+    scopes text COLLATE pg_catalog."default" NOT NULL,
+    disabled boolean NOT NULL DEFAULT false,
+    created_by text COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT oauth_clients_pkey PRIMARY KEY (client_id)
+);
+
+ALTER TABLE IF EXISTS public.oauth_clients
+    OWNER to postgres;
+
+
+-- PAYMENT AUTHORIZATIONS
+-- A customer's hold for one merchant, captured by that merchant's payment.
+ALTER TABLE IF EXISTS public.holds
+    ADD COLUMN IF NOT EXISTS merchant_id bigint,
+    ADD CONSTRAINT holds_merchant_id_fkey FOREIGN KEY (merchant_id)
+        REFERENCES public.merchants (merchant_id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE NO ACTION;
+
+
+-- API KEY SECRETS ENCRYPTED AT REST
+-- The old hash was itself the signing key, so keys stored that way are
+-- revoked and must be reissued.
+ALTER TABLE IF EXISTS public.api_keys
+    ADD COLUMN IF NOT EXISTS encrypted_secret text COLLATE pg_catalog."default";
+
+UPDATE public.api_keys
+    SET status = 'revoked', revoked_at = COALESCE(revoked_at, now()), encrypted_secret = ''
+    WHERE encrypted_secret IS NULL;
+
+ALTER TABLE IF EXISTS public.api_keys
+    ALTER COLUMN encrypted_secret SET NOT NULL,
+    DROP COLUMN IF EXISTS secret_hash;
@@ -2,9 +2,12 @@ package config
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
@@ -26,6 +29,50 @@ func GetEnv(key, fallback string) string {
 	return val
 }
 
+// GetDuration parses key as a Go duration such as "45m", falling back when
+// it is unset or invalid.
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// GetFloat parses key as a number, falling back when it is unset or invalid.
+func GetFloat(key string, fallback float64) float64 {
+	f, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
+// GetKey decodes key as base64, returning nil when it is unset. A value that
+// is not valid base64 is used as raw bytes.
+func GetKey(key string) []byte {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(val); err == nil {
+		return b
+	}
+	return []byte(val)
+}
+
+// GetTime parses key as an RFC 3339 timestamp or a YYYY-MM-DD date, returning
+// the zero time when it is unset or invalid.
+func GetTime(key string) time.Time {
+	val := os.Getenv(key)
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 func SetupDatabase() *gorm.DB {
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one key of a KeySet. Retired keys may have no private half;
+// they are only used to verify tokens signed before the rotation.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	PublicKey crypto.PublicKey
+}
+
+// KeySet holds the keys tokens are signed and verified with. Asymmetric sets
+// sign with the active key and verify with any key in the set, matched on the
+// token's kid header. Without key files the set falls back to HS256 with a
+// shared secret.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	secret []byte
+}
+
+// NewHMACKeySet signs and verifies HS256 tokens with secret. Nothing can be
+// published at the JWKS endpoint, so other services need the secret too.
+func NewHMACKeySet(secret string) *KeySet {
+	return &KeySet{secret: []byte(secret)}
+}
+
+// LoadKeySet reads every *.pem file in dir as a key whose kid is the file
+// name without the extension. RSA keys sign RS256 and P-256 keys ES256.
+// activeKID names the key new tokens are signed with and must have a private
+// key. To rotate, add the new key, point activeKID at it and restart; the old
+// file keeps verifying tokens until it is removed.
+func LoadKeySet(dir, activeKID string) (*KeySet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	ks := &KeySet{keys: map[string]*SigningKey{}}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := loadPEMKey(kid, file)
+		if err != nil {
+			return nil, err
+		}
+		ks.keys[kid] = key
+	}
+
+	ks.active = ks.keys[activeKID]
+	if ks.active == nil {
+		return nil, fmt.Errorf("active signing key %q not found in %s", activeKID, dir)
+	}
+	if ks.active.Private == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeKID)
+	}
+	return ks, nil
+}
+
+func loadPEMKey(kid, file string) (*SigningKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", file)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	key := &SigningKey{ID: kid}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.Private = signer
+		key.PublicKey = signer.Public()
+	} else {
+		key.PublicKey = parsed
+	}
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s: only P-256 EC keys are supported", file)
+		}
+		key.Method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", file, pub)
+	}
+	return key, nil
+}
+
+// SetupKeys loads the signing keys from JWT_KEYS_DIR, signing with the key
+// named by JWT_ACTIVE_KID. Without JWT_KEYS_DIR it falls back to HS256 with
+// JWT_SECRET.
+func SetupKeys() *KeySet {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		return NewHMACKeySet(os.Getenv("JWT_SECRET"))
+	}
+	ks, err := LoadKeySet(dir, os.Getenv("JWT_ACTIVE_KID"))
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+	return ks
+}
+
+// Methods lists the signing algorithms tokens may use. Anything else,
+// including "none", is rejected when parsing.
+func (k *KeySet) Methods() []string {
+	if k.active == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range k.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Sign signs claims with the active key, naming it in the kid header.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if k.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.secret)
+	}
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	token.Header["kid"] = k.active.ID
+	return token.SignedString(k.active.Private)
+}
+
+// Keyfunc finds the key that verifies token. It is meant for jwt.Parse
+// together with jwt.WithValidMethods(k.Methods()).
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if k.active == nil {
+		return k.secret, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	key := k.keys[kid]
+	if key == nil {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("signing algorithm does not match key")
+	}
+	return key.PublicKey, nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS returns the public half of every key, active and retired, sorted by
+// kid. HMAC sets publish nothing.
+func (k *KeySet) JWKS() []JWK {
+	jwks := []JWK{}
+	for _, key := range k.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64(pub.N.Bytes())
+			jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.Kty = "EC"
+			jwk.Crv = "P-256"
+			jwk.X = b64(pub.X.FillBytes(make([]byte, 32)))
+			jwk.Y = b64(pub.Y.FillBytes(make([]byte, 32)))
+		}
+		jwks = append(jwks, jwk)
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"wallet-topup/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeys(t *testing.T) string {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2024-rsa.pem"), rsaPEM, 0o600))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	ecPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2025-ec.pem"), ecPEM, 0o600))
+
+	return dir
+}
+
+func parse(ks *config.KeySet, token string) error {
+	_, err := jwt.Parse(token, ks.Keyfunc, jwt.WithValidMethods(ks.Methods()))
+	return err
+}
+
+func TestKeySet_SignsWithActiveKeyAndVerifiesRetired(t *testing.T) {
+	dir := writeKeys(t)
+	old, err := config.LoadKeySet(dir, "2024-rsa")
+	require.NoError(t, err)
+	current, err := config.LoadKeySet(dir, "2025-ec")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ES256", "RS256"}, current.Methods())
+
+	issued, err := config.NewJWTIssuer(current, config.TokenPolicy{}).IssueToken("somchai", []string{"customer"}, nil, "")
+	require.NoError(t, err)
+	header, _, err := jwt.NewParser().ParseUnverified(issued.Token, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-ec", header.Header["kid"])
+	assert.Equal(t, "ES256", header.Method.Alg())
+	assert.NoError(t, parse(current, issued.Token))
+
+	beforeRotation, err := old.Sign(jwt.MapClaims{"sub": "somchai", "exp": time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	assert.NoError(t, parse(current, beforeRotation), "retired key still verifies")
+
+	jwks := current.JWKS()
+	require.Len(t, jwks, 2)
+	assert.Equal(t, "RSA", jwks[0].Kty)
+	assert.Equal(t, "EC", jwks[1].Kty)
+	assert.Equal(t, "P-256", jwks[1].Crv)
+}
+
+func TestKeySet_RejectsOtherAlgorithms(t *testing.T) {
+	ks, err := config.LoadKeySet(writeKeys(t), "2025-ec")
+	require.NoError(t, err)
+
+	hs256, err := config.NewHMACKeySet("shared").Sign(jwt.MapClaims{"sub": "somchai"})
+	require.NoError(t, err)
+	assert.Error(t, parse(ks, hs256))
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "somchai"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	assert.Error(t, parse(ks, none))
+}
+
+func TestLoadKeySet_ActiveKeyMustExist(t *testing.T) {
+	_, err := config.LoadKeySet(writeKeys(t), "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"wallet-topup/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy maps roles to the scopes they grant. A scope is "resource:action",
+// such as "topup:write". "resource:*" grants every action on the resource and
+// "*" grants everything.
+type Policy struct {
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// LoadPolicy reads a role-to-scope policy from a YAML file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+	if len(p.Roles) == 0 {
+		return nil, fmt.Errorf("policy %s defines no roles", path)
+	}
+	return &p, nil
+}
+
+// Allows reports whether any of roles grants scope.
+func (p *Policy) Allows(roles []string, scope string) bool {
+	for _, role := range roles {
+		if model.ScopeAllows(p.Roles[role], scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the scopes that roles do not grant, in the order given.
+func (p *Policy) Missing(roles []string, scopes ...string) []string {
+	var missing []string
+	for _, scope := range scopes {
+		if !p.Allows(roles, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -1,24 +1,134 @@
 package config
 
 import (
-	"os"
+	"fmt"
+	"strings"
 	"time"
 
+	"wallet-topup/model"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func GenerateToken() string {
-	secret := os.Getenv("JWT_SECRET")
+// AccessTokenTTL is the lifetime of access tokens. Clients keep a session
+// going with refresh tokens instead of long-lived access tokens.
+const AccessTokenTTL = 15 * time.Minute
+
+// TokenTypeAccess is the "typ" claim of access tokens. Refresh tokens are
+// opaque and never JWTs, so only access tokens are accepted as bearer tokens.
+const TokenTypeAccess = "access"
+
+// TokenPolicy is what issued tokens say about where they come from and who
+// they are for, and how much clock drift validation tolerates.
+type TokenPolicy struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// LoadTokenPolicy reads JWT_ISSUER, JWT_AUDIENCE and JWT_LEEWAY.
+func LoadTokenPolicy() TokenPolicy {
+	return TokenPolicy{
+		Issuer:   GetEnv("JWT_ISSUER", "wallet-topup"),
+		Audience: GetEnv("JWT_AUDIENCE", "wallet-topup-api"),
+		Leeway:   GetDuration("JWT_LEEWAY", 30*time.Second),
+	}
+}
+
+// JWTIssuer signs tokens with the active key of a KeySet. They carry the
+// subject, its roles and, for customers, the wallet user ID. Every token gets
+// a unique jti so it can be revoked.
+type JWTIssuer struct {
+	keys   *KeySet
+	policy TokenPolicy
+}
+
+func NewJWTIssuer(keys *KeySet, policy TokenPolicy) *JWTIssuer {
+	return &JWTIssuer{keys: keys, policy: policy}
+}
+
+func (i *JWTIssuer) IssueToken(subject string, roles []string, userID *uint, sessionID string) (*model.AccessToken, error) {
+	token, claims := i.baseClaims(subject, roles)
+	if userID != nil {
+		claims["uid"] = *userID
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+	return i.sign(claims, token)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user": "admin",
-		"exp":  time.Now().Add(24 * time.Hour).Unix(),
-	})
+// IssueClientToken signs a client_credentials token. The subject is
+// "client:<id>" so it cannot be mistaken for a username, and the granted
+// scopes are listed space-separated in "scope".
+func (i *JWTIssuer) IssueClientToken(clientID string, scopes []string) (*model.AccessToken, error) {
+	token, claims := i.baseClaims("client:"+clientID, []string{model.RoleService})
+	claims["client_id"] = clientID
+	claims["scope"] = strings.Join(scopes, " ")
+	return i.sign(claims, token)
+}
 
-	tokenStr, err := token.SignedString([]byte(secret))
+func (i *JWTIssuer) sign(claims jwt.MapClaims, token *model.AccessToken) (*model.AccessToken, error) {
+	signed, err := i.keys.Sign(claims)
 	if err != nil {
-		return ""
+		return nil, err
+	}
+	token.Token = signed
+	return token, nil
+}
+
+func (i *JWTIssuer) baseClaims(subject string, roles []string) (*model.AccessToken, jwt.MapClaims) {
+	now := time.Now()
+	token := &model.AccessToken{
+		ID:        uuid.NewString(),
+		ExpiresAt: now.Add(AccessTokenTTL),
+	}
+	return token, jwt.MapClaims{
+		"iss":   i.policy.Issuer,
+		"aud":   i.policy.Audience,
+		"sub":   subject,
+		"typ":   TokenTypeAccess,
+		"roles": roles,
+		"jti":   token.ID,
+		"iat":   now.Unix(),
+		"nbf":   now.Unix(),
+		"exp":   token.ExpiresAt.Unix(),
+	}
+}
+
+// TokenValidator checks bearer tokens against a KeySet and a TokenPolicy. A
+// token must be signed with an allowed algorithm, come from the configured
+// issuer for the configured audience, have an exp, be inside its nbf/exp
+// window give or take the leeway, and be an access token.
+type TokenValidator struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+func NewTokenValidator(keys *KeySet, policy TokenPolicy) *TokenValidator {
+	return &TokenValidator{
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(keys.Methods()),
+			jwt.WithIssuer(policy.Issuer),
+			jwt.WithAudience(policy.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(policy.Leeway),
+		),
 	}
+}
 
-	return tokenStr
+// Validate returns the claims of a valid token. The error says why a token
+// was rejected and never includes the token.
+func (v *TokenValidator) Validate(tokenStr string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenStr, claims, v.keys.Keyfunc); err != nil {
+		return nil, err
+	}
+	if typ, _ := claims["typ"].(string); typ != TokenTypeAccess {
+		return nil, fmt.Errorf("token type %q is not %q", typ, TokenTypeAccess)
+	}
+	return claims, nil
 }
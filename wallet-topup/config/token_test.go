@@ -0,0 +1,78 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"wallet-topup/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTokenPolicy = config.TokenPolicy{Issuer: "wallet-topup", Audience: "wallet-topup-api", Leeway: 30 * time.Second}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": "wallet-topup",
+		"aud": "wallet-topup-api",
+		"sub": "somchai",
+		"typ": config.TokenTypeAccess,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}
+}
+
+func TestTokenValidator_AcceptsIssuedTokens(t *testing.T) {
+	keys := config.NewHMACKeySet("secret")
+	issued, err := config.NewJWTIssuer(keys, testTokenPolicy).IssueToken("somchai", []string{"customer"}, nil, "sess-1")
+	require.NoError(t, err)
+
+	claims, err := config.NewTokenValidator(keys, testTokenPolicy).Validate(issued.Token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "somchai", claims["sub"])
+	assert.Equal(t, issued.ID, claims["jti"])
+}
+
+func TestTokenValidator_Rejects(t *testing.T) {
+	keys := config.NewHMACKeySet("secret")
+	validator := config.NewTokenValidator(keys, testTokenPolicy)
+
+	cases := map[string]func(jwt.MapClaims){
+		"wrong issuer":     func(c jwt.MapClaims) { c["iss"] = "someone-else" },
+		"missing issuer":   func(c jwt.MapClaims) { delete(c, "iss") },
+		"wrong audience":   func(c jwt.MapClaims) { c["aud"] = "other-api" },
+		"missing exp":      func(c jwt.MapClaims) { delete(c, "exp") },
+		"expired":          func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		"not yet valid":    func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(time.Minute).Unix() },
+		"refresh token":    func(c jwt.MapClaims) { c["typ"] = "refresh" },
+		"missing type":     func(c jwt.MapClaims) { delete(c, "typ") },
+		"issued in future": func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() },
+	}
+	for name, mutate := range cases {
+		claims := validClaims()
+		mutate(claims)
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+
+		_, err = validator.Validate(token)
+		assert.Error(t, err, name)
+		assert.NotContains(t, err.Error(), token, name)
+	}
+}
+
+func TestTokenValidator_AllowsClockSkew(t *testing.T) {
+	keys := config.NewHMACKeySet("secret")
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+	claims["nbf"] = time.Now().Add(10 * time.Second).Unix()
+	token, err := keys.Sign(claims)
+	require.NoError(t, err)
+
+	_, err = config.NewTokenValidator(keys, testTokenPolicy).Validate(token)
+
+	assert.NoError(t, err)
+}
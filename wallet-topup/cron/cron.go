@@ -0,0 +1,160 @@
+// Package cron parses five-field cron expressions ("minute hour day-of-month
+// month day-of-week") and computes their next occurrence.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type field struct {
+	min, max int
+}
+
+var fields = []field{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week, Sunday = 0
+}
+
+var descriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day fields were "*". When both
+	// are restricted a day matches if either field matches, as in Vixie cron.
+	domStar, dowStar bool
+}
+
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := descriptors[expr]; ok {
+		expr = d
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, errors.New("cron expression must have 5 fields")
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron field %q: %w", part, err)
+		}
+		bits[i] = b
+	}
+	// Allow 7 as an alias for Sunday.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}, nil
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	max := f.max
+	if f.min == 0 && f.max == 6 {
+		max = 7
+	}
+
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return 0, errors.New("bad step")
+			}
+			rangeExpr, step = item[:i], s
+		}
+
+		lo, hi := f.min, max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, errors.New("bad range")
+			}
+		default:
+			n, err := strconv.Atoi(rangeExpr)
+			if err != nil {
+				return 0, errors.New("bad value")
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = max
+			}
+		}
+		if lo < f.min || hi > max || lo > hi {
+			return 0, errors.New("value out of range")
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's
+// location. It returns the zero time if nothing matches within five years
+// (e.g. "0 0 30 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,35 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"wallet-topup/cron"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"0 9 1 * *":    time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+		"@monthly":     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		"*/15 * * * *": time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC),
+		"0 8 * * 1-5":  time.Date(2025, 1, 16, 8, 0, 0, 0, time.UTC),
+		"0 0 * * 7":    time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":   time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+	}
+	for expr, want := range cases {
+		s, err := cron.Parse(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, want, s.Next(from), expr)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a b c d e"} {
+		_, err := cron.Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
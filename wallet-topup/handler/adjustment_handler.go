@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdjustmentHandler struct {
+	svc    model.AdjustmentService
+	logger model.Logger
+}
+
+func NewAdjustmentHandler(svc model.AdjustmentService, logger model.Logger) *AdjustmentHandler {
+	return &AdjustmentHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+type proposeAdjustmentRequest struct {
+	UserID     uint    `json:"user_id" binding:"required"`
+	Direction  string  `json:"direction" binding:"required,oneof=credit debit"`
+	Amount     float64 `json:"amount" binding:"required,gt=0,money"`
+	ReasonCode string  `json:"reason_code" binding:"required"`
+	Evidence   string  `json:"evidence" binding:"required"`
+}
+
+type reviewAdjustmentRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+func (h *AdjustmentHandler) Propose(c *gin.Context) {
+	var req proposeAdjustmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	adjustment, err := h.svc.ProposeAdjustment(c.Request.Context(), middleware.Actor(c), &model.Adjustment{
+		UserID:     req.UserID,
+		Direction:  req.Direction,
+		Amount:     req.Amount,
+		ReasonCode: req.ReasonCode,
+		Evidence:   req.Evidence,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, adjustmentResponse(adjustment))
+}
+
+func (h *AdjustmentHandler) Approve(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req reviewAdjustmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	adjustment, err := h.svc.ApproveAdjustment(c.Request.Context(), middleware.Actor(c), id, req.Note)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustmentResponse(adjustment))
+}
+
+func (h *AdjustmentHandler) Reject(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req reviewAdjustmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	adjustment, err := h.svc.RejectAdjustment(c.Request.Context(), middleware.Actor(c), id, req.Note)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustmentResponse(adjustment))
+}
+
+func (h *AdjustmentHandler) Get(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	adjustment, history, err := h.svc.GetAdjustment(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := adjustmentResponse(adjustment)
+	res["history"] = auditResponse(history)
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AdjustmentHandler) List(c *gin.Context) {
+	adjustments, err := h.svc.ListAdjustments(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		h.logger.Error("list adjustments error:", err)
+		respondError(c, model.Unavailable("failed to list adjustments", err))
+		return
+	}
+
+	res := make([]gin.H, 0, len(adjustments))
+	for i := range adjustments {
+		res = append(res, adjustmentResponse(&adjustments[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"adjustments": res})
+}
+
+func adjustmentResponse(a *model.Adjustment) gin.H {
+	res := gin.H{
+		"adjustment_id": a.AdjustmentID,
+		"user_id":       a.UserID,
+		"direction":     a.Direction,
+		"amount":        a.Amount,
+		"reason_code":   a.ReasonCode,
+		"evidence":      a.Evidence,
+		"status":        a.Status,
+		"proposed_by":   a.ProposedBy,
+		"reviewed_by":   a.ReviewedBy,
+		"review_note":   a.ReviewNote,
+		"created_at":    a.CreatedAt.Format(time.RFC3339),
+		"reviewed_at":   nil,
+	}
+	if a.ReviewedAt != nil {
+		res["reviewed_at"] = a.ReviewedAt.Format(time.RFC3339)
+	}
+	return res
+}
+
+func auditResponse(entries []model.AuditLog) []gin.H {
+	res := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, gin.H{
+			"actor":      e.Actor,
+			"action":     e.Action,
+			"details":    json.RawMessage(e.Details),
+			"created_at": e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return res
+}
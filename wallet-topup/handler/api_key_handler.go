@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	svc    model.APIKeyService
+	logger model.Logger
+}
+
+func NewAPIKeyHandler(svc model.APIKeyService, logger model.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (h *APIKeyHandler) Issue(c *gin.Context) {
+	merchantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid merchant id")
+		return
+	}
+
+	issued, err := h.svc.IssueKey(c.Request.Context(), middleware.Actor(c), uint(merchantID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, issuedKeyResponse(issued))
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	merchantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid merchant id")
+		return
+	}
+
+	keys, err := h.svc.ListKeys(c.Request.Context(), uint(merchantID))
+	if err != nil {
+		h.logger.Error("list api keys error:", err)
+		respondError(c, model.Unavailable("failed to list api keys", err))
+		return
+	}
+
+	res := make([]gin.H, 0, len(keys))
+	for i := range keys {
+		res = append(res, apiKeyResponse(&keys[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": res})
+}
+
+func (h *APIKeyHandler) Rotate(c *gin.Context) {
+	issued, err := h.svc.RotateKey(c.Request.Context(), middleware.Actor(c), c.Param("key_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := issuedKeyResponse(issued)
+	res["replaces"] = c.Param("key_id")
+	c.JSON(http.StatusCreated, res)
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	key, err := h.svc.RevokeKey(c.Request.Context(), middleware.Actor(c), c.Param("key_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeyResponse(key))
+}
+
+// issuedKeyResponse is the only response that contains the secret.
+func issuedKeyResponse(issued *model.IssuedAPIKey) gin.H {
+	res := apiKeyResponse(issued.Key)
+	res["secret"] = issued.Secret
+	return res
+}
+
+func apiKeyResponse(k *model.APIKey) gin.H {
+	res := gin.H{
+		"key_id":      k.KeyID,
+		"merchant_id": k.MerchantID,
+		"status":      k.Status,
+		"created_by":  k.CreatedBy,
+		"created_at":  k.CreatedAt.Format(time.RFC3339),
+		"expires_at":  nil,
+		"revoked_at":  nil,
+	}
+	if k.ExpiresAt != nil {
+		res["expires_at"] = k.ExpiresAt.Format(time.RFC3339)
+	}
+	if k.RevokedAt != nil {
+		res["revoked_at"] = k.RevokedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"wallet-topup/config"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	svc    model.AuthService
+	logger model.Logger
+}
+
+func NewAuthHandler(svc model.AuthService, logger model.Logger) *AuthHandler {
+	return &AuthHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type createCredentialRequest struct {
+	Username string   `json:"username" binding:"required,max=64"`
+	Password string   `json:"password" binding:"required,min=8"`
+	UserID   *uint    `json:"user_id"`
+	Roles    []string `json:"roles" binding:"required,min=1"`
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req loginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	pair, err := h.svc.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+// Refresh rotates a refresh token into a new token pair.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	pair, err := h.svc.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.svc.Logout(c.Request.Context()); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged_out"})
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	if err := h.svc.LogoutAll(c.Request.Context()); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged_out_everywhere"})
+}
+
+func tokenResponse(pair *model.TokenPair) gin.H {
+	return gin.H{
+		"token":         pair.AccessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(config.AccessTokenTTL / time.Second),
+		"refresh_token": pair.RefreshToken,
+	}
+}
+
+func (h *AuthHandler) CreateCredential(c *gin.Context) {
+	var req createCredentialRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	cred, err := h.svc.CreateCredential(c.Request.Context(), req.Username, req.Password, req.UserID, req.Roles)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"username":   cred.Username,
+		"user_id":    cred.UserID,
+		"roles":      cred.RoleList(),
+		"created_at": cred.CreatedAt.Format(time.RFC3339),
+	})
+}
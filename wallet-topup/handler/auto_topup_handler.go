@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AutoTopUpHandler struct {
+	svc    model.AutoTopUpService
+	logger model.Logger
+}
+
+func NewAutoTopUpHandler(svc model.AutoTopUpService, logger model.Logger) *AutoTopUpHandler {
+	return &AutoTopUpHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (h *AutoTopUpHandler) Get(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+
+	cfg, err := h.svc.GetConfig(c.Request.Context(), uint(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, autoTopUpResponse(cfg))
+}
+
+func (h *AutoTopUpHandler) Save(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+
+	var req struct {
+		Threshold     float64 `json:"threshold" binding:"gte=0,money"`
+		Amount        float64 `json:"amount" binding:"required,gt=0,money"`
+		DailyLimit    float64 `json:"daily_limit" binding:"gte=0,money"`
+		PaymentMethod string  `json:"payment_method" binding:"required,payment_method"`
+		PaymentToken  string  `json:"payment_token" binding:"required"`
+		Enabled       bool    `json:"enabled"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	cfg, err := h.svc.SaveConfig(c.Request.Context(), &model.AutoTopUpConfig{
+		UserID:        uint(userID),
+		Threshold:     req.Threshold,
+		Amount:        req.Amount,
+		DailyLimit:    req.DailyLimit,
+		PaymentMethod: req.PaymentMethod,
+		PaymentToken:  req.PaymentToken,
+		Enabled:       req.Enabled,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, autoTopUpResponse(cfg))
+}
+
+func autoTopUpResponse(cfg *model.AutoTopUpConfig) gin.H {
+	return gin.H{
+		"user_id":        cfg.UserID,
+		"threshold":      cfg.Threshold,
+		"amount":         cfg.Amount,
+		"daily_limit":    cfg.DailyLimit,
+		"payment_method": cfg.PaymentMethod,
+		"enabled":        cfg.Enabled,
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBatchFileSize = 5 << 20
+
+type BatchHandler struct {
+	svc    model.BatchService
+	logger model.Logger
+}
+
+func NewBatchHandler(svc model.BatchService, logger model.Logger) *BatchHandler {
+	return &BatchHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// Submit accepts a multipart form with a "reference" field and a "file" CSV
+// (user_id,amount,reference). Processing continues in the background; poll
+// Get for progress.
+func (h *BatchHandler) Submit(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchFileSize)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		invalidInput(c, "csv file is required")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		invalidInput(c, "Invalid input")
+		return
+	}
+	defer file.Close()
+
+	batch, created, err := h.svc.SubmitBatch(c.Request.Context(), c.PostForm("reference"), file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusAccepted
+		go func() {
+			if err := h.svc.ProcessBatch(model.WithSystem(context.Background(), "batch"), batch.BatchID); err != nil {
+				h.logger.Error("batch processing error:", err)
+			}
+		}()
+	}
+	c.JSON(status, batchResponse(batch))
+}
+
+func (h *BatchHandler) Get(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	batch, err := h.svc.GetBatch(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batchResponse(batch))
+}
+
+func (h *BatchHandler) Rows(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListRows(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, gin.H{
+			"row":       row.RowNumber,
+			"user_id":   row.UserID,
+			"amount":    row.Amount,
+			"reference": row.Reference,
+			"status":    row.Status,
+			"error":     row.Error,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"batch_id": id, "rows": res})
+}
+
+func batchResponse(b *model.Batch) gin.H {
+	res := gin.H{
+		"batch_id":       b.BatchID,
+		"reference":      b.Reference,
+		"status":         b.Status,
+		"total_rows":     b.TotalRows,
+		"processed_rows": b.SucceededRows + b.FailedRows,
+		"succeeded_rows": b.SucceededRows,
+		"failed_rows":    b.FailedRows,
+		"total_amount":   b.TotalAmount,
+		"created_at":     b.CreatedAt.Format(time.RFC3339),
+		"completed_at":   nil,
+	}
+	if b.CompletedAt != nil {
+		res["completed_at"] = b.CompletedAt.Format(time.RFC3339)
+	}
+	return res
+}
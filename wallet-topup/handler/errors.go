@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError writes err in the standard envelope shared with the
+// middleware.
+func respondError(c *gin.Context, err error) {
+	middleware.RespondError(c, err)
+}
+
+// invalidInput rejects a request body or parameter that could not be parsed.
+func invalidInput(c *gin.Context, message string) {
+	respondError(c, model.Invalid(message))
+}
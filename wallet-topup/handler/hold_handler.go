@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	svc    model.HoldService
+	logger model.Logger
+}
+
+func NewHoldHandler(svc model.HoldService, logger model.Logger) *HoldHandler {
+	return &HoldHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (h *HoldHandler) Place(c *gin.Context) {
+	var req struct {
+		UserID           uint    `json:"user_id" binding:"required"`
+		Amount           float64 `json:"amount" binding:"required,gt=0,money"`
+		Reason           string  `json:"reason" binding:"max=255"`
+		ExpiresInSeconds int64   `json:"expires_in_seconds" binding:"gte=0"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInSeconds) * time.Second
+	hold, err := h.svc.PlaceHold(c.Request.Context(), req.UserID, req.Amount, req.Reason, ttl)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, holdResponse(hold))
+}
+
+func (h *HoldHandler) Capture(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	hold, err := h.svc.CaptureHold(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, holdResponse(hold))
+}
+
+func (h *HoldHandler) Release(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	hold, err := h.svc.ReleaseHold(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, holdResponse(hold))
+}
+
+func (h *HoldHandler) Balance(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+
+	balance, err := h.svc.GetBalance(c.Request.Context(), uint(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	holds := make([]gin.H, 0, len(balance.Holds))
+	for i := range balance.Holds {
+		holds = append(holds, holdResponse(&balance.Holds[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":           balance.UserID,
+		"balance":           balance.Balance,
+		"held_balance":      balance.Held,
+		"available_balance": balance.Available,
+		"holds":             holds,
+	})
+}
+
+func holdResponse(hold *model.Hold) gin.H {
+	res := gin.H{
+		"hold_id":    hold.HoldID,
+		"user_id":    hold.UserID,
+		"amount":     hold.Amount,
+		"reason":     hold.Reason,
+		"status":     hold.Status,
+		"expires_at": nil,
+		"created_at": hold.CreatedAt.Format(time.RFC3339),
+	}
+	if hold.ExpiresAt != nil {
+		res["expires_at"] = hold.ExpiresAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"wallet-topup/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KeysHandler struct {
+	keys *config.KeySet
+}
+
+func NewKeysHandler(keys *config.KeySet) *KeysHandler {
+	return &KeysHandler{keys: keys}
+}
+
+// JWKS publishes the public signing keys so other services can verify our
+// tokens. Retired keys stay listed until their files are removed.
+func (h *KeysHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": h.keys.JWKS()})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KYCHandler struct {
+	svc    model.KYCService
+	logger model.Logger
+}
+
+func NewKYCHandler(svc model.KYCService, logger model.Logger) *KYCHandler {
+	return &KYCHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+type changeTierRequest struct {
+	Tier   string `json:"tier" binding:"required,oneof=basic verified premium"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (h *KYCHandler) ChangeTier(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+	var req changeTierRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.svc.ChangeTier(c.Request.Context(), middleware.Actor(c), uint(userID), req.Tier, req.Reason)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, kycResponse(user))
+}
+
+func (h *KYCHandler) Get(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+
+	user, history, err := h.svc.GetKYC(c.Request.Context(), uint(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := kycResponse(user)
+	res["history"] = auditResponse(history)
+	c.JSON(http.StatusOK, res)
+}
+
+func kycResponse(u *model.User) gin.H {
+	tier := model.TierFor(u.KYCTier)
+	return gin.H{
+		"user_id": u.UserID,
+		"tier":    tier.Name,
+		"limits": gin.H{
+			"max_transaction": tier.MaxTransaction,
+			"daily":           tier.DailyLimit,
+			"max_balance":     tier.MaxBalance,
+		},
+		"features": gin.H{
+			"transfers":   tier.Transfers,
+			"withdrawals": tier.Withdrawals,
+		},
+	}
+}
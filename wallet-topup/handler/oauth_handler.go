@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"wallet-topup/config"
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthHandler serves the OAuth2 token and introspection endpoints. Both take
+// form-encoded bodies and authenticate the client with HTTP Basic auth or
+// client_id and client_secret form fields. Errors use the OAuth2 error codes.
+type OAuthHandler struct {
+	svc         model.OAuthService
+	validator   *config.TokenValidator
+	revocations model.TokenRevocations
+	logger      model.Logger
+}
+
+func NewOAuthHandler(svc model.OAuthService, validator *config.TokenValidator, revocations model.TokenRevocations, logger model.Logger) *OAuthHandler {
+	return &OAuthHandler{
+		svc:         svc,
+		validator:   validator,
+		revocations: revocations,
+		logger:      logger,
+	}
+}
+
+func clientCredentials(c *gin.Context) (string, string) {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		return id, secret
+	}
+	return c.PostForm("client_id"), c.PostForm("client_secret")
+}
+
+// oauthError writes an RFC 6749 section 5.2 error body instead of the API's
+// usual error envelope, which OAuth2 client libraries do not understand.
+func oauthError(c *gin.Context, status int, code, description string) {
+	body := gin.H{"error": code}
+	if description != "" {
+		body["error_description"] = description
+	}
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	c.JSON(status, body)
+}
+
+// Token runs the client_credentials grant.
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	switch grantType := c.PostForm("grant_type"); grantType {
+	case "client_credentials":
+	case "":
+		oauthError(c, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		return
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+		return
+	}
+
+	clientID, secret := clientCredentials(c)
+	token, scopes, err := h.svc.IssueToken(c.Request.Context(), clientID, secret, strings.Fields(c.PostForm("scope")))
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidClient):
+			oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+		case errors.Is(err, model.ErrInvalidScope):
+			oauthError(c, http.StatusBadRequest, "invalid_scope", err.Error())
+		case model.KindOf(err) == model.KindUnavailable:
+			h.logger.Error("oauth token error:", err)
+			oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "")
+		default:
+			h.logger.Error("oauth token error:", err)
+			oauthError(c, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": token.Token,
+		"token_type":   "Bearer",
+		"expires_in":   int(time.Until(token.ExpiresAt).Round(time.Second) / time.Second),
+		"scope":        strings.Join(scopes, " "),
+	})
+}
+
+// Introspect tells a registered client whether a token is currently valid
+// and what it carries. Invalid, expired and revoked tokens all come back as
+// just {"active": false}.
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	clientID, secret := clientCredentials(c)
+	if _, err := h.svc.AuthenticateClient(c.Request.Context(), clientID, secret); err != nil {
+		oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+		return
+	}
+
+	inactive := gin.H{"active": false}
+	claims, err := h.validator.Validate(c.PostForm("token"))
+	if err != nil {
+		c.JSON(http.StatusOK, inactive)
+		return
+	}
+	p := middleware.PrincipalFromClaims(claims)
+	if h.revocations != nil {
+		revoked, err := h.revocations.IsRevoked(c.Request.Context(), p)
+		if err != nil {
+			h.logger.Error("token revocation check error:", err)
+			oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "")
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusOK, inactive)
+			return
+		}
+	}
+
+	res := gin.H{
+		"active":     true,
+		"token_type": "Bearer",
+		"sub":        p.Subject,
+		"roles":      p.Roles,
+		"jti":        p.TokenID,
+		"iss":        claims["iss"],
+		"aud":        claims["aud"],
+		"iat":        p.IssuedAt.Unix(),
+		"exp":        p.ExpiresAt.Unix(),
+	}
+	if p.Scopes != nil {
+		res["scope"] = strings.Join(p.Scopes, " ")
+	}
+	if clientID, ok := claims["client_id"].(string); ok {
+		res["client_id"] = clientID
+	}
+	if p.UserID != nil {
+		res["uid"] = *p.UserID
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+type createClientRequest struct {
+	ClientID string   `json:"client_id" binding:"required"`
+	Name     string   `json:"name" binding:"max=255"`
+	Scopes   []string `json:"scopes" binding:"required,min=1"`
+}
+
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	var req createClientRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	client, secret, err := h.svc.CreateClient(c.Request.Context(), middleware.Actor(c), req.ClientID, req.Name, req.Scopes)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"client_id":     client.ClientID,
+		"name":          client.Name,
+		"scopes":        client.ScopeList(),
+		"client_secret": secret,
+		"created_by":    client.CreatedBy,
+		"created_at":    client.CreatedAt.Format(time.RFC3339),
+	})
+}
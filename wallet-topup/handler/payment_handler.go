@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	svc    model.PaymentService
+	logger model.Logger
+}
+
+func NewPaymentHandler(svc model.PaymentService, logger model.Logger) *PaymentHandler {
+	return &PaymentHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// Authorize lets a customer reserve funds for a merchant, which the
+// merchant's API key later captures with a payment.
+func (h *PaymentHandler) Authorize(c *gin.Context) {
+	var req struct {
+		MerchantID       uint    `json:"merchant_id" binding:"required"`
+		UserID           uint    `json:"user_id"`
+		Amount           float64 `json:"amount" binding:"required,gt=0,money"`
+		ExpiresInSeconds int64   `json:"expires_in_seconds" binding:"required,gt=0"`
+	}
+	if !decodeJSON(c, &req) {
+		return
+	}
+	if req.UserID == 0 {
+		req.UserID = principalUserID(c)
+	}
+	if !validateRequest(c, &req) {
+		return
+	}
+	if req.UserID == 0 {
+		invalidInput(c, "user_id is required")
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInSeconds) * time.Second
+	hold, err := h.svc.AuthorizePayment(c.Request.Context(), req.MerchantID, req.UserID, req.Amount, ttl)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := holdResponse(hold)
+	res["authorization_id"] = hold.HoldID
+	res["merchant_id"] = req.MerchantID
+	c.JSON(http.StatusOK, res)
+}
+
+// Create takes a payment. Merchant API keys must pass the authorization_id
+// of a payment authorization the customer granted them.
+func (h *PaymentHandler) Create(c *gin.Context) {
+	var req struct {
+		MerchantID      uint    `json:"merchant_id"`
+		UserID          uint    `json:"user_id" binding:"required"`
+		Amount          float64 `json:"amount" binding:"required,gt=0,money"`
+		OrderReference  string  `json:"order_reference" binding:"required,max=64"`
+		Description     string  `json:"description" binding:"max=255"`
+		AuthorizationID string  `json:"authorization_id" binding:"omitempty,uuid"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+	merchantID, ok := callerMerchant(c, req.MerchantID)
+	if !ok {
+		return
+	}
+
+	payment, err := h.svc.CreatePayment(c.Request.Context(), merchantID, req.UserID, req.Amount, req.OrderReference, req.Description, req.AuthorizationID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paymentResponse(payment))
+}
+
+func (h *PaymentHandler) Get(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MerchantID uint `form:"merchant_id"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		invalidInput(c, "Invalid input")
+		return
+	}
+	merchantID, ok := callerMerchant(c, req.MerchantID)
+	if !ok {
+		return
+	}
+
+	payment, err := h.svc.GetPayment(c.Request.Context(), merchantID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paymentResponse(payment))
+}
+
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MerchantID uint    `json:"merchant_id"`
+		Amount     float64 `json:"amount" binding:"required,gt=0,money"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+	merchantID, ok := callerMerchant(c, req.MerchantID)
+	if !ok {
+		return
+	}
+
+	payment, err := h.svc.RefundPayment(c.Request.Context(), merchantID, id, req.Amount)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paymentResponse(payment))
+}
+
+// callerMerchant picks the merchant a payment request is for. A merchant API
+// key always acts for its own merchant, and naming another one is refused;
+// other callers must name it in the request.
+func callerMerchant(c *gin.Context, requested uint) (uint, bool) {
+	if p, ok := model.PrincipalFrom(c.Request.Context()); ok && p.MerchantID != nil {
+		if requested != 0 && requested != *p.MerchantID {
+			respondError(c, model.ErrMerchantMismatch)
+			return 0, false
+		}
+		return *p.MerchantID, true
+	}
+	if requested == 0 {
+		invalidInput(c, "merchant_id is required")
+		return 0, false
+	}
+	return requested, true
+}
+
+func paymentResponse(p *model.Payment) gin.H {
+	return gin.H{
+		"payment_id":      p.PaymentID,
+		"merchant_id":     p.MerchantID,
+		"user_id":         p.UserID,
+		"amount":          p.Amount,
+		"refunded_amount": p.RefundedAmount,
+		"order_reference": p.OrderReference,
+		"description":     p.Description,
+		"status":          p.Status,
+		"created_at":      p.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	svc    model.ScheduleService
+	logger model.Logger
+}
+
+func NewScheduleHandler(svc model.ScheduleService, logger model.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (h *ScheduleHandler) Create(c *gin.Context) {
+	var req struct {
+		UserID        uint    `json:"user_id" binding:"required"`
+		Amount        float64 `json:"amount" binding:"required,gt=0,money"`
+		PaymentMethod string  `json:"payment_method" binding:"required,payment_method"`
+		PaymentToken  string  `json:"payment_token" binding:"required"`
+		Cron          string  `json:"cron" binding:"required"`
+		FailurePolicy string  `json:"failure_policy" binding:"omitempty,oneof=retry skip"`
+		MaxRetries    int     `json:"max_retries" binding:"gte=0"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	schedule, err := h.svc.CreateSchedule(c.Request.Context(), &model.TopUpSchedule{
+		UserID:        req.UserID,
+		Amount:        req.Amount,
+		PaymentMethod: req.PaymentMethod,
+		PaymentToken:  req.PaymentToken,
+		CronExpr:      req.Cron,
+		FailurePolicy: req.FailurePolicy,
+		MaxRetries:    req.MaxRetries,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
+
+func (h *ScheduleHandler) Get(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	schedule, err := h.svc.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
+
+func (h *ScheduleHandler) Pause(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	schedule, err := h.svc.PauseSchedule(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
+
+func (h *ScheduleHandler) Resume(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	schedule, err := h.svc.ResumeSchedule(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduleResponse(schedule))
+}
+
+func (h *ScheduleHandler) Runs(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	runs, err := h.svc.ListRuns(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := make([]gin.H, 0, len(runs))
+	for _, run := range runs {
+		res = append(res, gin.H{
+			"run_id":         run.RunID,
+			"attempt":        run.Attempt,
+			"status":         run.Status,
+			"transaction_id": run.TransactionID,
+			"error":          run.Error,
+			"created_at":     run.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": id, "runs": res})
+}
+
+func scheduleResponse(s *model.TopUpSchedule) gin.H {
+	res := gin.H{
+		"schedule_id":    s.ScheduleID,
+		"user_id":        s.UserID,
+		"amount":         s.Amount,
+		"payment_method": s.PaymentMethod,
+		"cron":           s.CronExpr,
+		"failure_policy": s.FailurePolicy,
+		"max_retries":    s.MaxRetries,
+		"retry_count":    s.RetryCount,
+		"status":         s.Status,
+		"next_run_at":    s.NextRunAt.Format(time.RFC3339),
+		"last_run_at":    nil,
+	}
+	if s.LastRunAt != nil {
+		res["last_run_at"] = s.LastRunAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	svc    model.TransferService
+	logger model.Logger
+}
+
+func NewTransferHandler(svc model.TransferService, logger model.Logger) *TransferHandler {
+	return &TransferHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (h *TransferHandler) Create(c *gin.Context) {
+	var req struct {
+		FromUserID uint    `json:"from_user_id" binding:"required"`
+		ToUserID   uint    `json:"to_user_id" binding:"required"`
+		Amount     float64 `json:"amount" binding:"required,gt=0,money"`
+		Note       string  `json:"note" binding:"max=255"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	transfer, err := h.svc.CreateTransfer(c.Request.Context(), req.FromUserID, req.ToUserID, req.Amount, req.Note)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transfer_id":  transfer.TransferID,
+		"from_user_id": transfer.FromUserID,
+		"to_user_id":   transfer.ToUserID,
+		"amount":       transfer.Amount,
+		"note":         transfer.Note,
+		"status":       transfer.Status,
+		"created_at":   transfer.CreatedAt.Format(time.RFC3339),
+	})
+}
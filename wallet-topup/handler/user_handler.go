@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	svc    model.UserService
+	logger model.Logger
+}
+
+func NewUserHandler(svc model.UserService, logger model.Logger) *UserHandler {
+	return &UserHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+type createUserRequest struct {
+	ExternalRef string `json:"external_ref" binding:"required,max=64"`
+	FullName    string `json:"full_name"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+}
+
+type updateProfileRequest struct {
+	FullName *string `json:"full_name"`
+	Email    *string `json:"email"`
+	Phone    *string `json:"phone"`
+}
+
+// Create returns 201 for a new user and 200 with the existing user when the
+// external reference is already registered.
+func (h *UserHandler) Create(c *gin.Context) {
+	var req createUserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, created, err := h.svc.CreateUser(c.Request.Context(), &model.User{
+		ExternalRef: req.ExternalRef,
+		FullName:    req.FullName,
+		Email:       req.Email,
+		Phone:       req.Phone,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, userResponse(user))
+}
+
+func (h *UserHandler) Get(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+
+	user, err := h.svc.GetUser(c.Request.Context(), uint(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (h *UserHandler) Lookup(c *gin.Context) {
+	ref := c.Query("external_ref")
+	if ref == "" {
+		invalidInput(c, "external_ref is required")
+		return
+	}
+
+	user, err := h.svc.FindByExternalRef(c.Request.Context(), ref)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+func (h *UserHandler) Update(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+	var req updateProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.svc.UpdateProfile(c.Request.Context(), uint(userID), model.ProfileUpdate{
+		FullName: req.FullName,
+		Email:    req.Email,
+		Phone:    req.Phone,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, userResponse(user))
+}
+
+func userResponse(u *model.User) gin.H {
+	return gin.H{
+		"user_id":      u.UserID,
+		"external_ref": u.ExternalRef,
+		"full_name":    u.FullName,
+		"email":        u.Email,
+		"phone":        u.Phone,
+		"status":       u.Status,
+		"kyc_tier":     model.TierFor(u.KYCTier).Name,
+		"balance":      u.Balance,
+		"created_at":   u.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// maxJSONBodySize caps JSON request bodies. The largest legitimate body is a
+// few hundred bytes.
+const maxJSONBodySize = 64 << 10
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(jsonFieldName)
+	v.RegisterValidation("money", validMoney)
+	v.RegisterValidation("payment_method", func(fl validator.FieldLevel) bool {
+		return model.IsPaymentMethod(fl.Field().String())
+	})
+}
+
+// jsonFieldName makes validation errors name fields as clients send them.
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// validMoney accepts amounts with at most two decimal places.
+func validMoney(fl validator.FieldLevel) bool {
+	cents := fl.Field().Float() * 100
+	return math.Abs(cents-math.Round(cents)) < 1e-6
+}
+
+// bindJSON decodes the body into req and checks its `binding` tags. On
+// failure it has already written the error response and returns false.
+func bindJSON(c *gin.Context, req any) bool {
+	return decodeJSON(c, req) && validateRequest(c, req)
+}
+
+// decodeJSON strictly decodes a single JSON object of at most
+// maxJSONBodySize bytes into req, rejecting fields req does not declare. Use
+// it on its own when defaults must be filled in before validateRequest.
+func decodeJSON(c *gin.Context, req any) bool {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxJSONBodySize)
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(req)
+	if err == nil && dec.More() {
+		err = errors.New("trailing data")
+	}
+	if err != nil {
+		respondError(c, decodeError(err))
+		return false
+	}
+	return true
+}
+
+// idParam returns the :id path parameter, which names a record by UUID.
+// Anything else is rejected here rather than sent to the database. On failure
+// it has already written the error response and returns false.
+func idParam(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		invalidInput(c, "id must be a UUID")
+		return "", false
+	}
+	return id, true
+}
+
+func validateRequest(c *gin.Context, req any) bool {
+	err := binding.Validator.ValidateStruct(req)
+	if err == nil {
+		return true
+	}
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		respondError(c, model.Invalid("Invalid input"))
+		return false
+	}
+	fields := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		fields[fe.Field()] = fieldMessage(fe)
+	}
+	respondError(c, invalidFields("request validation failed", fields))
+	return false
+}
+
+func invalidFields(message string, fields map[string]string) error {
+	return model.Invalid(message).WithDetails(map[string]any{"fields": fields})
+}
+
+func decodeError(err error) error {
+	var tooLarge *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return model.TooLarge(fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit))
+	case errors.Is(err, io.EOF):
+		return model.Invalid("request body is required")
+	case errors.As(err, &typeErr):
+		return invalidFields("request validation failed", map[string]string{
+			typeErr.Field: "must be " + jsonType(typeErr.Type.Kind()),
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return invalidFields("request has unknown fields", map[string]string{field: "unknown field"})
+	}
+	return model.Invalid("request body must be a single JSON object")
+}
+
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	} else if fe.Kind() == reflect.Slice {
+		unit = " items"
+	}
+	switch fe.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "payment_method":
+		return "must be one of: " + strings.Join(model.PaymentMethods, ", ")
+	case "money":
+		return "must have at most 2 decimal places"
+	case "numeric":
+		return "must contain only digits"
+	case "email":
+		return "must be an email address"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "min":
+		return "must have at least " + fe.Param() + unit
+	case "max":
+		return "must have at most " + fe.Param() + unit
+	case "len":
+		return "must have exactly " + fe.Param() + unit
+	}
+	return "is invalid"
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"wallet-topup/middleware"
 	"wallet-topup/model"
 
 	"github.com/gin-gonic/gin"
@@ -21,59 +23,118 @@ func NewWalletHandler(svc model.WalletService, logger model.Logger) *WalletHandl
 	}
 }
 
+// Verify creates a verified top-up either from user_id, amount and
+// payment_method or, when quote_token is given, from the terms of that quote.
+// Customers may leave out user_id to top up their own wallet.
 func (h *WalletHandler) Verify(c *gin.Context) {
 	var req struct {
-		UserID        uint    `json:"user_id"`
-		Amount        float64 `json:"amount"`
-		PaymentMethod string  `json:"payment_method"`
+		UserID        uint    `json:"user_id" binding:"required_without=QuoteToken"`
+		Amount        float64 `json:"amount" binding:"required_without=QuoteToken,omitempty,gt=0,money"`
+		PaymentMethod string  `json:"payment_method" binding:"required_without=QuoteToken,omitempty,payment_method"`
+		QuoteToken    string  `json:"quote_token"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	if !decodeJSON(c, &req) {
+		return
+	}
+	if req.UserID == 0 {
+		req.UserID = principalUserID(c)
+	}
+	if !validateRequest(c, &req) {
 		return
 	}
 
-	user, err := h.svc.GetUserByID(req.UserID)
-	if err != nil || user == nil {
-		h.logger.Error("user not found:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
+	if req.QuoteToken != "" {
+		txn, err := h.svc.VerifyQuote(c.Request.Context(), req.QuoteToken)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, verifyResponse(txn))
 		return
 	}
 
 	txn, err := h.svc.VerifyTransaction(c.Request.Context(), req.UserID, req.Amount, req.PaymentMethod)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verifyResponse(txn))
+}
+
+func (h *WalletHandler) Quote(c *gin.Context) {
+	var req struct {
+		UserID        uint    `json:"user_id" binding:"required"`
+		Amount        float64 `json:"amount" binding:"required,gt=0,money"`
+		PaymentMethod string  `json:"payment_method" binding:"required,payment_method"`
+	}
+	if !decodeJSON(c, &req) {
+		return
+	}
+	if req.UserID == 0 {
+		req.UserID = principalUserID(c)
+	}
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	quote, err := h.svc.QuoteTopUp(c.Request.Context(), req.UserID, req.Amount, req.PaymentMethod)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"transaction_id": txn.TransactionID,
-		"user_id":        txn.UserID,
-		"amount":         txn.Amount,
-		"payment_method": txn.PaymentMethod,
-		"status":         txn.Status,
-		"expires_at":     txn.ExpiresAt.Format(time.RFC3339),
+		"quote_id":        quote.QuoteID,
+		"user_id":         quote.UserID,
+		"amount":          quote.Amount,
+		"payment_method":  quote.PaymentMethod,
+		"fee":             quote.Fee,
+		"bonus":           quote.Bonus,
+		"you_pay":         quote.Charge(),
+		"you_receive":     quote.Credit(),
+		"limit_remaining": quote.LimitRemaining,
+		"expires_at":      quote.ExpiresAt.Format(time.RFC3339),
+		"quote_token":     quote.Token,
 	})
 }
 
 func (h *WalletHandler) Confirm(c *gin.Context) {
 	var req struct {
-		TransactionID string `json:"transaction_id"`
+		TransactionID string `json:"transaction_id" binding:"required,uuid"`
+		OTP           string `json:"otp" binding:"omitempty,len=6,numeric"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	txn, err := h.svc.ConfirmTransaction(c.Request.Context(), req.TransactionID)
+	var txn *model.Transaction
+	var err error
+	if req.OTP == "" {
+		txn, err = h.svc.ConfirmTransaction(c.Request.Context(), req.TransactionID)
+	} else {
+		txn, err = h.svc.ConfirmWithCode(c.Request.Context(), req.TransactionID, req.OTP)
+	}
+	var challenge *model.ChallengeRequiredError
+	if errors.As(err, &challenge) {
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":         "challenge_required",
+			"transaction_id": challenge.TransactionID,
+			"channel":        challenge.Channel,
+			"destination":    challenge.Destination,
+			"expires_at":     challenge.ExpiresAt.Format(time.RFC3339),
+		})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	user, err := h.svc.GetUserByID(txn.UserID)
 	if err != nil || user == nil {
 		h.logger.Error("failed to fetch user balance:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user balance"})
+		respondError(c, model.Unavailable("failed to fetch user balance", err))
 		return
 	}
 
@@ -85,3 +146,41 @@ func (h *WalletHandler) Confirm(c *gin.Context) {
 		"balance":        user.Balance,
 	})
 }
+
+// Extend pushes back the expiry of a verified transaction that the customer
+// is still paying for.
+func (h *WalletHandler) Extend(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	txn, err := h.svc.ExtendTransaction(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := verifyResponse(txn)
+	res["extensions"] = txn.Extensions
+	c.JSON(http.StatusOK, res)
+}
+
+func verifyResponse(txn *model.Transaction) gin.H {
+	return gin.H{
+		"transaction_id": txn.TransactionID,
+		"user_id":        txn.UserID,
+		"amount":         txn.Amount,
+		"payment_method": txn.PaymentMethod,
+		"status":         txn.Status,
+		"expires_at":     txn.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// principalUserID returns the wallet owned by the caller, or 0 for callers
+// such as admins that have none.
+func principalUserID(c *gin.Context) uint {
+	if p := middleware.Principal(c); p != nil && p.UserID != nil {
+		return *p.UserID
+	}
+	return 0
+}
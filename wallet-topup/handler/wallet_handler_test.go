@@ -6,10 +6,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"wallet-topup/handler"
+	"wallet-topup/middleware"
 	"wallet-topup/mocks"
 	"wallet-topup/model"
 
@@ -23,6 +25,8 @@ func setupRouter(h *handler.WalletHandler) *gin.Engine {
 	r := gin.Default()
 	r.POST("/wallet/verify", h.Verify)
 	r.POST("/wallet/confirm", h.Confirm)
+	r.POST("/wallet/quote", h.Quote)
+	r.POST("/wallet/transactions/:id/extend", h.Extend)
 	return r
 }
 
@@ -48,7 +52,6 @@ func TestVerify_Success(t *testing.T) {
 	txnRepo.On("CreateTransaction", txn).Return(nil)
 
 	svc := &mocks.WalletServiceMock{}
-	svc.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
 	svc.On("VerifyTransaction", mock.Anything, uint(1), 100.50, "credit_card").Return(txn, nil)
 
 	h := handler.NewWalletHandler(svc, logger)
@@ -84,8 +87,8 @@ func TestVerify_UserNotFound(t *testing.T) {
 	logger := new(mocks.LoggerMock)
 	svc := new(mocks.WalletServiceMock)
 
-	svc.On("GetUserByID", uint(99)).Return((*model.User)(nil), errors.New("not found"))
-	logger.On("Error", "user not found:", mock.Anything)
+	svc.On("VerifyTransaction", mock.Anything, uint(99), 100.0, "credit_card").
+		Return(nil, model.NotFound("user not found"))
 
 	h := handler.NewWalletHandler(svc, logger)
 	router := setupRouter(h)
@@ -102,16 +105,20 @@ func TestVerify_UserNotFound(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "not_found", res["code"])
+	assert.Equal(t, "user not found", res["message"])
 }
 
 func TestVerify_InvalidAmount(t *testing.T) {
 	logger := new(mocks.LoggerMock)
 	svc := new(mocks.WalletServiceMock)
 
-	svc.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
 	svc.On("VerifyTransaction", mock.Anything, uint(1), 0.0, "credit_card").
-		Return(nil, errors.New("amount must be greater than zero"))
+		Return(nil, model.Invalid("amount must be greater than zero"))
 
 	h := handler.NewWalletHandler(svc, logger)
 	router := setupRouter(h)
@@ -179,7 +186,7 @@ func TestConfirm_Expired(t *testing.T) {
 	txnID := uuid.New().String()
 
 	svc.On("ConfirmTransaction", mock.Anything, txnID).
-		Return(nil, errors.New("transaction has expired"))
+		Return(nil, model.Expired("transaction has expired"))
 
 	h := handler.NewWalletHandler(svc, logger)
 	router := setupRouter(h)
@@ -193,7 +200,7 @@ func TestConfirm_Expired(t *testing.T) {
 
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusGone, w.Code)
 }
 
 func TestConfirm_InvalidStatus(t *testing.T) {
@@ -203,7 +210,7 @@ func TestConfirm_InvalidStatus(t *testing.T) {
 	txnID := uuid.New().String()
 
 	svc.On("ConfirmTransaction", mock.Anything, txnID).
-		Return(nil, errors.New("transaction is already completed"))
+		Return(nil, model.AlreadyCompleted("transaction is already completed"))
 
 	h := handler.NewWalletHandler(svc, logger)
 	router := setupRouter(h)
@@ -217,5 +224,257 @@ func TestConfirm_InvalidStatus(t *testing.T) {
 
 	router.ServeHTTP(w, req)
 
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "already_completed", res["code"])
+}
+
+func TestConfirm_UnexpectedErrorIsHidden(t *testing.T) {
+	logger := new(mocks.LoggerMock)
+	svc := new(mocks.WalletServiceMock)
+
+	txnID := uuid.New().String()
+	svc.On("ConfirmTransaction", mock.Anything, txnID).
+		Return(nil, errors.New("pq: connection refused"))
+
+	h := handler.NewWalletHandler(svc, logger)
+	router := gin.Default()
+	router.Use(middleware.RequestIDMiddleware())
+	router.POST("/wallet/confirm", h.Confirm)
+
+	b, _ := json.Marshal(map[string]interface{}{"transaction_id": txnID})
+	req := httptest.NewRequest("POST", "/wallet/confirm", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "internal_error", res["code"])
+	assert.NotContains(t, res["message"], "pq:")
+	assert.Equal(t, "req-123", res["request_id"])
+	assert.Equal(t, "req-123", w.Header().Get("X-Request-ID"))
+}
+
+func TestConfirm_ChallengeRequired(t *testing.T) {
+	logger := new(mocks.LoggerMock)
+	svc := new(mocks.WalletServiceMock)
+
+	txnID := uuid.New().String()
+	svc.On("ConfirmTransaction", mock.Anything, txnID).Return(nil, &model.ChallengeRequiredError{
+		TransactionID: txnID,
+		Channel:       "sms",
+		Destination:   "081****678",
+		ExpiresAt:     time.Now().Add(5 * time.Minute),
+	})
+
+	h := handler.NewWalletHandler(svc, logger)
+	router := setupRouter(h)
+
+	b, _ := json.Marshal(map[string]interface{}{"transaction_id": txnID})
+	req := httptest.NewRequest("POST", "/wallet/confirm", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "challenge_required", res["status"])
+	assert.Equal(t, "sms", res["channel"])
+	assert.Equal(t, "081****678", res["destination"])
+}
+
+func TestConfirm_WithCode(t *testing.T) {
+	logger := new(mocks.LoggerMock)
+	svc := new(mocks.WalletServiceMock)
+
+	txnID := uuid.New().String()
+	svc.On("ConfirmWithCode", mock.Anything, txnID, "123456").
+		Return(&model.Transaction{TransactionID: txnID, UserID: 1, Amount: 50000, Status: "completed"}, nil)
+	svc.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 50000}, nil)
+
+	h := handler.NewWalletHandler(svc, logger)
+	router := setupRouter(h)
+
+	b, _ := json.Marshal(map[string]interface{}{"transaction_id": txnID, "otp": "123456"})
+	req := httptest.NewRequest("POST", "/wallet/confirm", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertNotCalled(t, "ConfirmTransaction", mock.Anything, mock.Anything)
+}
+
+func TestConfirm_OTPAttemptsExceeded(t *testing.T) {
+	logger := new(mocks.LoggerMock)
+	svc := new(mocks.WalletServiceMock)
+
+	txnID := uuid.New().String()
+	svc.On("ConfirmWithCode", mock.Anything, txnID, "000000").Return(nil, model.ErrOTPAttemptsExceeded)
+
+	h := handler.NewWalletHandler(svc, logger)
+	router := setupRouter(h)
+
+	b, _ := json.Marshal(map[string]interface{}{"transaction_id": txnID, "otp": "000000"})
+	req := httptest.NewRequest("POST", "/wallet/confirm", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestQuote_Success(t *testing.T) {
+	logger := new(mocks.LoggerMock)
+	svc := &mocks.WalletServiceMock{}
+	quote := &model.Quote{
+		QuoteID:        "q1",
+		UserID:         1,
+		Amount:         500,
+		PaymentMethod:  "credit_card",
+		LimitRemaining: 9500,
+		ExpiresAt:      time.Now().Add(5 * time.Minute),
+		Token:          "signed-token",
+	}
+	svc.On("QuoteTopUp", mock.Anything, uint(1), 500.0, "credit_card").Return(quote, nil)
+
+	h := handler.NewWalletHandler(svc, logger)
+	router := setupRouter(h)
+
+	body := `{"user_id":1,"amount":500,"payment_method":"credit_card"}`
+	req := httptest.NewRequest("POST", "/wallet/quote", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, 500.0, res["you_pay"])
+	assert.Equal(t, 500.0, res["you_receive"])
+	assert.Equal(t, 9500.0, res["limit_remaining"])
+	assert.Equal(t, "signed-token", res["quote_token"])
+	svc.AssertNotCalled(t, "VerifyTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerify_WithQuoteToken(t *testing.T) {
+	logger := new(mocks.LoggerMock)
+	svc := &mocks.WalletServiceMock{}
+	txn := &model.Transaction{
+		TransactionID: uuid.New().String(),
+		UserID:        1,
+		Amount:        500,
+		PaymentMethod: "credit_card",
+		Status:        "verified",
+		ExpiresAt:     time.Now().Add(15 * time.Minute),
+	}
+	svc.On("VerifyQuote", mock.Anything, "signed-token").Return(txn, nil)
+
+	h := handler.NewWalletHandler(svc, logger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest("POST", "/wallet/verify", bytes.NewBufferString(`{"quote_token":"signed-token"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertNotCalled(t, "VerifyTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func postJSON(router *gin.Engine, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var res map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	return w, res
+}
+
+func fieldErrors(res map[string]interface{}) map[string]interface{} {
+	details, _ := res["details"].(map[string]interface{})
+	fields, _ := details["fields"].(map[string]interface{})
+	return fields
+}
+
+func TestVerify_ValidationErrors(t *testing.T) {
+	svc := new(mocks.WalletServiceMock)
+	router := setupRouter(handler.NewWalletHandler(svc, new(mocks.LoggerMock)))
+
+	w, res := postJSON(router, "/wallet/verify", `{"amount": 10.005, "payment_method": "cash"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_request", res["code"])
+	fields := fieldErrors(res)
+	assert.Equal(t, "is required", fields["user_id"])
+	assert.Equal(t, "must have at most 2 decimal places", fields["amount"])
+	assert.Contains(t, fields["payment_method"], "must be one of")
+	svc.AssertNotCalled(t, "VerifyTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerify_UnknownFieldRejected(t *testing.T) {
+	svc := new(mocks.WalletServiceMock)
+	router := setupRouter(handler.NewWalletHandler(svc, new(mocks.LoggerMock)))
+
+	w, res := postJSON(router, "/wallet/verify", `{"user_id": 1, "amount": 100, "payment_method": "credit_card", "bonus": 50}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "unknown field", fieldErrors(res)["bonus"])
+}
+
+func TestVerify_WrongTypeRejected(t *testing.T) {
+	svc := new(mocks.WalletServiceMock)
+	router := setupRouter(handler.NewWalletHandler(svc, new(mocks.LoggerMock)))
+
+	w, res := postJSON(router, "/wallet/verify", `{"user_id": "1", "amount": 100, "payment_method": "credit_card"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "must be a non-negative integer", fieldErrors(res)["user_id"])
+}
+
+func TestVerify_BodyTooLarge(t *testing.T) {
+	svc := new(mocks.WalletServiceMock)
+	router := setupRouter(handler.NewWalletHandler(svc, new(mocks.LoggerMock)))
+
+	body := `{"payment_method": "` + strings.Repeat("x", 70<<10) + `"}`
+	w, res := postJSON(router, "/wallet/verify", body)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "payload_too_large", res["code"])
+}
+
+func TestConfirm_TransactionIDMustBeUUID(t *testing.T) {
+	svc := new(mocks.WalletServiceMock)
+	router := setupRouter(handler.NewWalletHandler(svc, new(mocks.LoggerMock)))
+
+	w, res := postJSON(router, "/wallet/confirm", `{"transaction_id": "abc123"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "must be a UUID", fieldErrors(res)["transaction_id"])
+	svc.AssertNotCalled(t, "ConfirmTransaction", mock.Anything, mock.Anything)
+}
+
+func TestExtend_IDMustBeUUID(t *testing.T) {
+	svc := new(mocks.WalletServiceMock)
+	router := setupRouter(handler.NewWalletHandler(svc, new(mocks.LoggerMock)))
+
+	w, res := postJSON(router, "/wallet/transactions/not-a-uuid/extend", `{}`)
+
 	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_request", res["code"])
+	svc.AssertNotCalled(t, "ExtendTransaction", mock.Anything, mock.Anything)
 }
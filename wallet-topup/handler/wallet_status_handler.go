@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WalletStatusHandler struct {
+	svc    model.WalletStatusService
+	logger model.Logger
+}
+
+func NewWalletStatusHandler(svc model.WalletStatusService, logger model.Logger) *WalletStatusHandler {
+	return &WalletStatusHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+type changeStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active frozen suspended closed"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (h *WalletStatusHandler) Change(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+	var req changeStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.svc.ChangeStatus(c.Request.Context(), middleware.Actor(c), uint(userID), req.Status, req.Reason)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id": user.UserID,
+		"status":  user.Status,
+		"balance": user.Balance,
+	})
+}
+
+func (h *WalletStatusHandler) History(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidInput(c, "Invalid user id")
+		return
+	}
+
+	changes, err := h.svc.GetStatusHistory(c.Request.Context(), uint(userID))
+	if err != nil {
+		h.logger.Error("status history error:", err)
+		respondError(c, model.Unavailable("failed to load status history", err))
+		return
+	}
+
+	res := make([]gin.H, 0, len(changes))
+	for _, change := range changes {
+		res = append(res, gin.H{
+			"from_status": change.FromStatus,
+			"to_status":   change.ToStatus,
+			"reason":      change.Reason,
+			"actor":       change.Actor,
+			"created_at":  change.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"user_id": userID, "history": res})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WithdrawalHandler struct {
+	svc    model.WithdrawalService
+	logger model.Logger
+}
+
+func NewWithdrawalHandler(svc model.WithdrawalService, logger model.Logger) *WithdrawalHandler {
+	return &WithdrawalHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+func (h *WithdrawalHandler) Request(c *gin.Context) {
+	var req struct {
+		UserID      uint    `json:"user_id" binding:"required"`
+		Amount      float64 `json:"amount" binding:"required,gt=0,money"`
+		BankAccount string  `json:"bank_account" binding:"required,max=34"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	withdrawal, err := h.svc.RequestWithdrawal(c.Request.Context(), req.UserID, req.Amount, req.BankAccount)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, withdrawalResponse(withdrawal))
+}
+
+func (h *WithdrawalHandler) Get(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	withdrawal, err := h.svc.GetWithdrawal(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, withdrawalResponse(withdrawal))
+}
+
+func (h *WithdrawalHandler) Refresh(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	withdrawal, err := h.svc.RefreshWithdrawal(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, withdrawalResponse(withdrawal))
+}
+
+func withdrawalResponse(w *model.Withdrawal) gin.H {
+	return gin.H{
+		"withdrawal_id":    w.WithdrawalID,
+		"user_id":          w.UserID,
+		"amount":           w.Amount,
+		"bank_account":     w.BankAccount,
+		"status":           w.Status,
+		"payout_reference": w.PayoutReference,
+		"failure_reason":   w.FailureReason,
+		"created_at":       w.CreatedAt.Format(time.RFC3339),
+		"updated_at":       w.UpdatedAt.Format(time.RFC3339),
+	}
+}
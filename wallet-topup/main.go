@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 	"wallet-topup/config"
 	"wallet-topup/handler"
 	"wallet-topup/logs"
 	"wallet-topup/middleware"
+	"wallet-topup/model"
+	"wallet-topup/provider"
 	"wallet-topup/repository"
 	"wallet-topup/service"
+	"wallet-topup/worker"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -25,24 +30,172 @@ func main() {
 
 	userRepo := repository.NewUserRepo(db)
 	txnRepo := repository.NewTransactionRepo(db)
+	withdrawalRepo := repository.NewWithdrawalRepo(db)
+	holdRepo := repository.NewHoldRepo(db)
+	merchantRepo := repository.NewMerchantRepo(db)
+	paymentRepo := repository.NewPaymentRepo(db)
+	scheduleRepo := repository.NewScheduleRepo(db)
+	autoTopUpRepo := repository.NewAutoTopUpRepo(db)
+	batchRepo := repository.NewBatchRepo(db)
+	adjustmentRepo := repository.NewAdjustmentRepo(db)
+	auditRepo := repository.NewAuditRepo(db)
+	walletStatusRepo := repository.NewWalletStatusRepo(db)
+	credentialRepo := repository.NewCredentialRepo(db)
+	apiKeyRepo := repository.NewAPIKeyRepo(db)
+	oauthClientRepo := repository.NewOAuthClientRepo(db)
+	txManager := repository.NewTxManager(db)
 
-	walletService := service.NewWalletService(txnRepo, userRepo, redisClient, logger)
+	walletOptions := []service.WalletOption{
+		service.WithQuoteSecret([]byte(config.GetEnv("QUOTE_SECRET", os.Getenv("JWT_SECRET")))),
+		service.WithMaxTransactionLifetime(config.GetDuration("TXN_MAX_LIFETIME", service.DefaultMaxTransactionLifetime)),
+		service.WithAuditRepository(auditRepo),
+	}
+	// Only the log-based sender exists so far, and it must never stand in for
+	// a real gateway, so step-up stays off unless explicitly asked for.
+	if config.GetEnv("OTP_DEV_SENDER", "false") == "true" {
+		logger.Warn("OTP_DEV_SENDER is enabled: step-up codes are written to the log, not sent to customers")
+		walletOptions = append(walletOptions,
+			service.WithStepUp(config.GetFloat("STEP_UP_THRESHOLD", 10000), provider.NewLogOTPSender(logger, os.Getenv("OTP_LOG_FILE"))))
+	} else {
+		logger.Warn("no OTP sender configured: step-up verification of large top-ups is disabled")
+	}
+	walletService := service.NewWalletService(txManager, txnRepo, userRepo, redisClient, logger, walletOptions...)
 	walletHandler := handler.NewWalletHandler(walletService, logger)
 
-	r := gin.Default()
+	userService := service.NewUserService(userRepo, logger)
+	userHandler := handler.NewUserHandler(userService, logger)
+
+	paymentProvider := provider.NewFakePaymentProvider()
+	autoTopUpService := service.NewAutoTopUpService(autoTopUpRepo, userRepo, walletService, paymentProvider, redisClient, logger)
+	autoTopUpHandler := handler.NewAutoTopUpHandler(autoTopUpService, logger)
+
+	// Debits made through debitTx may trigger an auto top-up once committed.
+	debitTx := service.WatchBalances(txManager, autoTopUpService)
+
+	transferService := service.NewTransferService(debitTx, logger)
+	transferHandler := handler.NewTransferHandler(transferService, logger)
+
+	holdService := service.NewHoldService(debitTx, holdRepo, userRepo, logger)
+	holdHandler := handler.NewHoldHandler(holdService, logger)
+
+	payoutProvider := provider.NewFakePayoutProvider(time.Minute)
+	withdrawalService := service.NewWithdrawalService(debitTx, withdrawalRepo, payoutProvider, logger)
+	withdrawalHandler := handler.NewWithdrawalHandler(withdrawalService, logger)
+
+	paymentService := service.NewPaymentService(debitTx, paymentRepo, merchantRepo, logger)
+	paymentHandler := handler.NewPaymentHandler(paymentService, logger)
+
+	scheduleService := service.NewScheduleService(scheduleRepo, walletService, paymentProvider, logger)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService, logger)
+
+	batchService := service.NewBatchService(txManager, batchRepo, userRepo, logger)
+	batchHandler := handler.NewBatchHandler(batchService, logger)
+
+	adjustmentService := service.NewAdjustmentService(debitTx, adjustmentRepo, auditRepo, logger)
+	adjustmentHandler := handler.NewAdjustmentHandler(adjustmentService, logger)
+
+	walletStatusService := service.NewWalletStatusService(txManager, walletStatusRepo, logger)
+	walletStatusHandler := handler.NewWalletStatusHandler(walletStatusService, logger)
+
+	kycService := service.NewKYCService(txManager, userRepo, auditRepo, logger)
+	kycHandler := handler.NewKYCHandler(kycService, logger)
+
+	signingKeys := config.SetupKeys()
+	tokenPolicy := config.LoadTokenPolicy()
+	tokenIssuer := config.NewJWTIssuer(signingKeys, tokenPolicy)
+	authService := service.NewAuthService(credentialRepo, userRepo, tokenIssuer, redisClient, logger)
+	authHandler := handler.NewAuthHandler(authService, logger)
+	keysHandler := handler.NewKeysHandler(signingKeys)
 
-	r.POST("/login", func(c *gin.Context) {
-		token := config.GenerateToken()
-		c.JSON(200, gin.H{"token": token})
+	tokenValidator := config.NewTokenValidator(signingKeys, tokenPolicy)
+	revocations := service.NewRevocationList(redisClient)
+	oauthService := service.NewOAuthService(oauthClientRepo, tokenIssuer, logger)
+	oauthHandler := handler.NewOAuthHandler(oauthService, tokenValidator, revocations, logger)
+
+	apiKeySecrets, err := service.NewSecretBox(config.GetKey("API_KEY_ENCRYPTION_KEY"))
+	if err != nil {
+		log.Fatal("Invalid API_KEY_ENCRYPTION_KEY:", err)
+	}
+	if os.Getenv("API_KEY_ENCRYPTION_KEY") == "" {
+		logger.Warn("API_KEY_ENCRYPTION_KEY is not set: merchant API keys issued now stop working after a restart")
+	}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, merchantRepo, auditRepo, redisClient, apiKeySecrets, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, logger)
+	if username, password := os.Getenv("BOOTSTRAP_ADMIN_USERNAME"), os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"); username != "" && password != "" {
+		if _, err := authService.CreateCredential(context.Background(), username, password, nil, []string{model.RoleAdmin}); err != nil {
+			logger.Info("bootstrap admin not created: ", err)
+		}
+	}
+
+	go worker.Every(model.WithSystem(context.Background(), "hold-expiry"), time.Minute, func(ctx context.Context) {
+		holdService.ReleaseExpiredHolds(ctx)
+	})
+	go worker.Every(model.WithSystem(context.Background(), "schedules"), time.Minute, func(ctx context.Context) {
+		scheduleService.RunDueSchedules(ctx)
+	})
+	go worker.Every(model.WithSystem(context.Background(), "batch"), 5*time.Minute, func(ctx context.Context) {
+		batchService.ResumeUnfinishedBatches(ctx)
 	})
 
-	auth := middleware.JWTAuthMiddleware()
+	r := gin.Default()
+	r.Use(middleware.RequestIDMiddleware())
+
+	r.POST("/login", authHandler.Login)
+	r.POST("/refresh", authHandler.Refresh)
+	r.GET("/.well-known/jwks.json", keysHandler.JWKS)
+	r.POST("/oauth/token", oauthHandler.Token)
+	r.POST("/oauth/introspect", oauthHandler.Introspect)
+
+	// The old anonymous admin login, for local development only.
+	if config.GetEnv("AUTH_DEV_LOGIN", "false") == "true" {
+		logger.Warn("AUTH_DEV_LOGIN is enabled: POST /login/dev issues admin tokens without a password")
+		r.POST("/login/dev", func(c *gin.Context) {
+			token, err := tokenIssuer.IssueToken("dev-admin", []string{model.RoleAdmin}, nil, "")
+			if err != nil {
+				c.JSON(500, gin.H{"error": "failed to issue token"})
+				return
+			}
+			c.JSON(200, gin.H{"token": token.Token})
+		})
+	}
+
+	auth := middleware.BearerOrAPIKey(
+		middleware.JWTAuthMiddleware(tokenValidator, revocations, logger),
+		middleware.APIKeyAuthMiddleware(apiKeyService, logger),
+	)
+	policy, err := config.LoadPolicy(config.GetEnv("AUTH_POLICY_FILE", "policy.yaml"))
+	if err != nil {
+		log.Fatal("Failed to load authorization policy:", err)
+	}
+	scope := func(scopes ...string) gin.HandlerFunc {
+		return middleware.RequireScopes(policy, scopes...)
+	}
 
-	api := r.Group("/api", auth)
-	{
-		api.POST("/verify", walletHandler.Verify)
-		api.POST("/confirm", walletHandler.Confirm)
+	apiVersions := []apiVersion{
+		{
+			prefix:       "/api/v1",
+			register:     registerV1,
+			deprecatedAt: config.GetTime("API_V1_DEPRECATED_AT"),
+			sunset:       config.GetTime("API_V1_SUNSET"),
+		},
 	}
+	mountAPI(r, apiVersions, config.GetTime("API_ALIAS_SUNSET"), auth, &apiHandlers{
+		auth:         authHandler,
+		wallet:       walletHandler,
+		user:         userHandler,
+		transfer:     transferHandler,
+		withdrawal:   withdrawalHandler,
+		hold:         holdHandler,
+		payment:      paymentHandler,
+		schedule:     scheduleHandler,
+		autoTopUp:    autoTopUpHandler,
+		batch:        batchHandler,
+		adjustment:   adjustmentHandler,
+		walletStatus: walletStatusHandler,
+		kyc:          kycHandler,
+		oauth:        oauthHandler,
+		apiKey:       apiKeyHandler,
+	}, scope)
 
 	port := os.Getenv("PORT")
 	if port == "" {
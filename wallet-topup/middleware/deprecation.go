@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation marks every response of a route group with a Deprecation
+// header (RFC 9745) carrying the date the routes were deprecated. When
+// sunset is set it also sends a Sunset header (RFC 8594) with the date the
+// routes go away, and successor, when set, is linked as the replacement. It
+// runs before authentication so rejected calls are warned too.
+func Deprecation(deprecatedAt, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if successor != "" {
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wallet-topup/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var deprecatedAt = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+func serveDeprecated(sunset time.Time, successor string) *httptest.ResponseRecorder {
+	r := gin.Default()
+	r.GET("/old", middleware.Deprecation(deprecatedAt, sunset, successor), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/old", nil))
+	return w
+}
+
+func TestDeprecation_Headers(t *testing.T) {
+	w := serveDeprecated(time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC), "/api/v2")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Sun, 31 Jan 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v2>; rel="successor-version"`, w.Header().Get("Link"))
+}
+
+func TestDeprecation_NoSunsetYet(t *testing.T) {
+	w := serveDeprecated(time.Time{}, "")
+
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Empty(t, w.Header().Get("Link"))
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse is the body of every error the API returns, from handlers and
+// middleware alike. Code is stable for clients to switch on; Message is for
+// people.
+type errorResponse struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details"`
+	RequestID string         `json:"request_id"`
+}
+
+var kindStatus = map[model.ErrorKind]int{
+	model.KindInvalid:          http.StatusBadRequest,
+	model.KindUnauthorized:     http.StatusUnauthorized,
+	model.KindForbidden:        http.StatusForbidden,
+	model.KindNotFound:         http.StatusNotFound,
+	model.KindConflict:         http.StatusConflict,
+	model.KindAlreadyCompleted: http.StatusConflict,
+	model.KindExpired:          http.StatusGone,
+	model.KindLimitExceeded:    http.StatusUnprocessableEntity,
+	model.KindRateLimited:      http.StatusTooManyRequests,
+	model.KindUnavailable:      http.StatusServiceUnavailable,
+	model.KindTooLarge:         http.StatusRequestEntityTooLarge,
+}
+
+// RespondError aborts the request with err in the standard envelope. Domain
+// errors get the status of their kind; anything else is an unexpected
+// failure and is reported as a 500 without leaking its text.
+func RespondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	res := errorResponse{
+		Code:      "internal_error",
+		Message:   "internal server error",
+		Details:   map[string]any{},
+		RequestID: RequestID(c),
+	}
+
+	var domainErr *model.Error
+	if errors.As(err, &domainErr) {
+		if s, ok := kindStatus[domainErr.Kind]; ok {
+			status = s
+		}
+		res.Code = domainErr.Code
+		res.Message = err.Error()
+		if domainErr.Details != nil {
+			res.Details = domainErr.Details
+		}
+	}
+
+	var batchErr *model.BatchValidationError
+	if errors.As(err, &batchErr) {
+		status = http.StatusUnprocessableEntity
+		res.Code = "invalid_rows"
+		res.Message = err.Error()
+		res.Details = map[string]any{"rows": batchErr.Rows}
+	}
+
+	c.AbortWithStatusJSON(status, res)
+}
@@ -1,35 +1,126 @@
 package middleware
 
 import (
-	"net/http"
-	"os"
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 
+	"wallet-topup/config"
+	"wallet-topup/model"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTAuthMiddleware() gin.HandlerFunc {
+// ActorKey is the gin context key holding the authenticated caller's
+// identity, taken from the token's "sub" claim (or "user" for older tokens).
+const ActorKey = "actor"
+
+// PrincipalKey is the gin context key holding the caller's *model.Principal.
+// The same principal is attached to the request context for services.
+const PrincipalKey = "principal"
+
+// JWTAuthMiddleware accepts requests with a bearer token that passes
+// validator and has not been revoked. revocations may be nil when revocation
+// is not in use. Rejections are logged with the reason and a fingerprint of
+// the token, never the token itself.
+func JWTAuthMiddleware(validator *config.TokenValidator, revocations model.TokenRevocations, logger model.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		auth := c.GetHeader("Authorization")
 		if !strings.HasPrefix(auth, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid token"})
-			c.Abort()
+			RespondError(c, model.ErrMissingToken)
 			return
 		}
 
 		tokenStr := strings.TrimPrefix(auth, "Bearer ")
 
-		secret := os.Getenv("JWT_SECRET")
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+		claims, err := validator.Validate(tokenStr)
+		if err != nil {
+			logger.Warnf("token rejected: %v (token=%s path=%s client=%s)", err, fingerprint(tokenStr), c.FullPath(), c.ClientIP())
+			RespondError(c, model.ErrInvalidToken)
 			return
 		}
 
+		p := PrincipalFromClaims(claims)
+		if revocations != nil {
+			revoked, err := revocations.IsRevoked(c.Request.Context(), p)
+			if err != nil {
+				logger.Error("token revocation check error:", err)
+				RespondError(c, model.Unavailable("unable to check token", err))
+				return
+			}
+			if revoked {
+				logger.Warnf("token rejected: revoked (jti=%s sub=%s path=%s)", p.TokenID, p.Subject, c.FullPath())
+				RespondError(c, model.ErrTokenRevoked)
+				return
+			}
+		}
+		setPrincipal(c, p)
+
 		c.Next()
 	}
 }
+
+// setPrincipal makes p the caller of the request, for handlers through the
+// gin context and for services through the request context.
+func setPrincipal(c *gin.Context, p *model.Principal) {
+	c.Set(ActorKey, p.Subject)
+	c.Set(PrincipalKey, p)
+	c.Request = c.Request.WithContext(model.WithPrincipal(c.Request.Context(), p))
+}
+
+// fingerprint identifies a token in logs without revealing it.
+func fingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:6])
+}
+
+// PrincipalFromClaims builds the caller from a validated token's claims.
+func PrincipalFromClaims(claims jwt.MapClaims) *model.Principal {
+	p := &model.Principal{}
+	if sub, _ := claims.GetSubject(); sub != "" {
+		p.Subject = sub
+	} else if user, ok := claims["user"].(string); ok {
+		p.Subject = user
+	}
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, r := range roles {
+			if role, ok := r.(string); ok && role != model.RoleSystem {
+				p.Roles = append(p.Roles, role)
+			}
+		}
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		p.Scopes = strings.Fields(scope)
+		if p.Scopes == nil {
+			p.Scopes = []string{}
+		}
+	}
+	if uid, ok := claims["uid"].(float64); ok && uid > 0 {
+		id := uint(uid)
+		p.UserID = &id
+	}
+	p.TokenID, _ = claims["jti"].(string)
+	p.SessionID, _ = claims["sid"].(string)
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		p.IssuedAt = iat.Time
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		p.ExpiresAt = exp.Time
+	}
+	return p
+}
+
+// Actor returns the identity stored by JWTAuthMiddleware, or "" when the
+// request was not authenticated.
+func Actor(c *gin.Context) string {
+	return c.GetString(ActorKey)
+}
+
+// Principal returns the caller stored by JWTAuthMiddleware, or nil when the
+// request was not authenticated.
+func Principal(c *gin.Context) *model.Principal {
+	p, _ := c.Get(PrincipalKey)
+	principal, _ := p.(*model.Principal)
+	return principal
+}
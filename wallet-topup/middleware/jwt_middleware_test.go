@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wallet-topup/config"
+	"wallet-topup/middleware"
+	"wallet-topup/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuthRouter(keys *config.KeySet, logger *mocks.LoggerMock) *gin.Engine {
+	policy := config.TokenPolicy{Issuer: "wallet-topup", Audience: "wallet-topup-api"}
+	r := gin.Default()
+	r.GET("/me", middleware.JWTAuthMiddleware(config.NewTokenValidator(keys, policy), nil, logger), func(c *gin.Context) {
+		c.String(http.StatusOK, middleware.Actor(c))
+	})
+	return r
+}
+
+func TestJWTAuthMiddleware_AcceptsAccessToken(t *testing.T) {
+	keys := config.NewHMACKeySet("secret")
+	issued, err := config.NewJWTIssuer(keys, config.TokenPolicy{Issuer: "wallet-topup", Audience: "wallet-topup-api"}).
+		IssueToken("somchai", []string{"customer"}, nil, "")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+issued.Token)
+	w := httptest.NewRecorder()
+	setupAuthRouter(keys, new(mocks.LoggerMock)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "somchai", w.Body.String())
+}
+
+func TestJWTAuthMiddleware_LogsReasonWithoutToken(t *testing.T) {
+	keys := config.NewHMACKeySet("secret")
+	token, err := keys.Sign(jwt.MapClaims{
+		"iss": "wallet-topup",
+		"aud": "wallet-topup-api",
+		"sub": "somchai",
+		"typ": config.TokenTypeAccess,
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	var logged string
+	logger := new(mocks.LoggerMock)
+	logger.On("Warnf", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		logged = fmt.Sprintf(args.String(0), args.Get(1).([]interface{})...)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	setupAuthRouter(keys, logger).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, logged, "expired")
+	assert.NotContains(t, logged, token)
+	assert.False(t, strings.Contains(logged, strings.Split(token, ".")[2]), "signature must not be logged")
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key holding the request ID.
+const RequestIDKey = "request_id"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware gives every request an ID, reusing the caller's
+// X-Request-ID when it looks sane so a request can be traced across
+// services. The ID is echoed in the response header and in error bodies.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the ID set by RequestIDMiddleware, or "" outside it.
+func RequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"wallet-topup/config"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScopes only lets the request through when the caller's roles grant
+// every one of scopes under policy. Tokens that list their own scopes, such
+// as OAuth client tokens, are limited to those instead. It must run after
+// JWTAuthMiddleware.
+// Denials name the missing permissions so clients can tell what to ask for.
+func RequireScopes(policy *config.Policy, scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := Principal(c)
+		if p == nil {
+			RespondError(c, model.ErrMissingToken)
+			return
+		}
+
+		if missing := missingScopes(policy, p, scopes); len(missing) > 0 {
+			RespondError(c, &model.Error{
+				Kind:    model.KindForbidden,
+				Code:    "insufficient_scope",
+				Message: "missing permission " + missing[0],
+				Details: map[string]any{"required": scopes, "missing": missing},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func missingScopes(policy *config.Policy, p *model.Principal, scopes []string) []string {
+	if p.Scopes == nil {
+		return policy.Missing(p.Roles, scopes...)
+	}
+	var missing []string
+	for _, scope := range scopes {
+		if !model.ScopeAllows(p.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wallet-topup/config"
+	"wallet-topup/middleware"
+	"wallet-topup/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicy = &config.Policy{Roles: map[string][]string{
+	model.RoleCustomer: {"topup:write", "txn:read"},
+	model.RoleService:  {"refund:*"},
+	model.RoleAdmin:    {"*"},
+}}
+
+func setupScopedRouter(roles ...string) *gin.Engine {
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, &model.Principal{Subject: "tester", Roles: roles})
+	})
+	r.POST("/refunds", middleware.RequireScopes(testPolicy, "refund:create"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestRequireScopes_Granted(t *testing.T) {
+	for _, role := range []string{model.RoleService, model.RoleAdmin} {
+		w := httptest.NewRecorder()
+		setupScopedRouter(role).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refunds", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code, role)
+	}
+}
+
+func TestRequireScopes_MissingPermission(t *testing.T) {
+	w := httptest.NewRecorder()
+	setupScopedRouter(model.RoleCustomer).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refunds", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "insufficient_scope", res["code"])
+	assert.Equal(t, []interface{}{"refund:create"}, res["details"].(map[string]interface{})["missing"])
+}
+
+func TestRequireScopes_NoPrincipal(t *testing.T) {
+	r := gin.Default()
+	r.GET("/open", middleware.RequireScopes(testPolicy, "txn:read"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/open", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequireScopes_TokenScopesReplaceRoles(t *testing.T) {
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, &model.Principal{
+			Subject: "client:payouts",
+			Roles:   []string{model.RoleAdmin},
+			Scopes:  []string{"txn:read"},
+		})
+	})
+	r.POST("/refunds", middleware.RequireScopes(testPolicy, "refund:create"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refunds", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AdjustmentRepoMock struct {
+	mock.Mock
+}
+
+func (m *AdjustmentRepoMock) CreateAdjustment(adjustment *model.Adjustment) error {
+	args := m.Called(adjustment)
+	return args.Error(0)
+}
+
+func (m *AdjustmentRepoMock) GetAdjustmentByID(adjustmentID string) (*model.Adjustment, error) {
+	args := m.Called(adjustmentID)
+	return args.Get(0).(*model.Adjustment), args.Error(1)
+}
+
+func (m *AdjustmentRepoMock) ListAdjustments(status string, limit int) ([]model.Adjustment, error) {
+	args := m.Called(status, limit)
+	return args.Get(0).([]model.Adjustment), args.Error(1)
+}
+
+func (m *AdjustmentRepoMock) UpdateAdjustmentReview(adjustment *model.Adjustment) error {
+	args := m.Called(adjustment)
+	return args.Error(0)
+}
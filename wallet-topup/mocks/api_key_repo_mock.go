@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type APIKeyRepoMock struct {
+	mock.Mock
+}
+
+func (m *APIKeyRepoMock) CreateAPIKey(key *model.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *APIKeyRepoMock) GetAPIKey(keyID string) (*model.APIKey, error) {
+	args := m.Called(keyID)
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (m *APIKeyRepoMock) ListAPIKeys(merchantID uint) ([]model.APIKey, error) {
+	args := m.Called(merchantID)
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *APIKeyRepoMock) ExpireAPIKey(keyID string, at time.Time) error {
+	args := m.Called(keyID, at)
+	return args.Error(0)
+}
+
+func (m *APIKeyRepoMock) RevokeAPIKey(keyID string, at time.Time) error {
+	args := m.Called(keyID, at)
+	return args.Error(0)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditRepoMock struct {
+	mock.Mock
+}
+
+func (m *AuditRepoMock) CreateAuditLog(entry *model.AuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *AuditRepoMock) ListAuditLogs(entityType, entityID string) ([]model.AuditLog, error) {
+	args := m.Called(entityType, entityID)
+	return args.Get(0).([]model.AuditLog), args.Error(1)
+}
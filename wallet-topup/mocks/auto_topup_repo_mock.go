@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AutoTopUpRepoMock struct {
+	mock.Mock
+}
+
+func (m *AutoTopUpRepoMock) GetConfig(userID uint) (*model.AutoTopUpConfig, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*model.AutoTopUpConfig), args.Error(1)
+}
+
+func (m *AutoTopUpRepoMock) SaveConfig(cfg *model.AutoTopUpConfig) error {
+	args := m.Called(cfg)
+	return args.Error(0)
+}
+
+func (m *AutoTopUpRepoMock) CreateAutoTopUp(topUp *model.AutoTopUp) error {
+	args := m.Called(topUp)
+	return args.Error(0)
+}
+
+func (m *AutoTopUpRepoMock) SumSucceededSince(userID uint, since time.Time) (float64, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).(float64), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type BatchRepoMock struct {
+	mock.Mock
+}
+
+func (m *BatchRepoMock) CreateBatch(batch *model.Batch, rows []model.BatchRow) error {
+	args := m.Called(batch, rows)
+	return args.Error(0)
+}
+
+func (m *BatchRepoMock) GetBatchByID(batchID string) (*model.Batch, error) {
+	args := m.Called(batchID)
+	return args.Get(0).(*model.Batch), args.Error(1)
+}
+
+func (m *BatchRepoMock) GetBatchByReference(reference string) (*model.Batch, error) {
+	args := m.Called(reference)
+	return args.Get(0).(*model.Batch), args.Error(1)
+}
+
+func (m *BatchRepoMock) ListRows(batchID string) ([]model.BatchRow, error) {
+	args := m.Called(batchID)
+	return args.Get(0).([]model.BatchRow), args.Error(1)
+}
+
+func (m *BatchRepoMock) ListPendingRows(batchID string, limit int) ([]model.BatchRow, error) {
+	args := m.Called(batchID, limit)
+	return args.Get(0).([]model.BatchRow), args.Error(1)
+}
+
+func (m *BatchRepoMock) UpdateRowStatus(batchID string, rowNumber int, fromStatus, status, errMsg string) error {
+	args := m.Called(batchID, rowNumber, fromStatus, status, errMsg)
+	return args.Error(0)
+}
+
+func (m *BatchRepoMock) RefreshBatchProgress(batchID string) (*model.Batch, error) {
+	args := m.Called(batchID)
+	return args.Get(0).(*model.Batch), args.Error(1)
+}
+
+func (m *BatchRepoMock) ListUnfinishedBatches(limit int) ([]model.Batch, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]model.Batch), args.Error(1)
+}
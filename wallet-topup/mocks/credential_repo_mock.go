@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type CredentialRepoMock struct {
+	mock.Mock
+}
+
+func (m *CredentialRepoMock) GetCredential(username string) (*model.Credential, error) {
+	args := m.Called(username)
+	return args.Get(0).(*model.Credential), args.Error(1)
+}
+
+func (m *CredentialRepoMock) CreateCredential(c *model.Credential) (bool, error) {
+	args := m.Called(c)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type HoldRepoMock struct {
+	mock.Mock
+}
+
+func (m *HoldRepoMock) CreateHold(hold *model.Hold) error {
+	args := m.Called(hold)
+	return args.Error(0)
+}
+
+func (m *HoldRepoMock) GetHoldByID(holdID string) (*model.Hold, error) {
+	args := m.Called(holdID)
+	return args.Get(0).(*model.Hold), args.Error(1)
+}
+
+func (m *HoldRepoMock) UpdateHoldStatus(holdID, fromStatus, toStatus string) error {
+	args := m.Called(holdID, fromStatus, toStatus)
+	return args.Error(0)
+}
+
+func (m *HoldRepoMock) SumActiveHolds(userID uint) (float64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *HoldRepoMock) ListActiveHolds(userID uint) ([]model.Hold, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.Hold), args.Error(1)
+}
+
+func (m *HoldRepoMock) ListExpiredHolds(now time.Time, limit int) ([]model.Hold, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]model.Hold), args.Error(1)
+}
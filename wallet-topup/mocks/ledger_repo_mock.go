@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerRepoMock struct {
+	mock.Mock
+}
+
+func (m *LedgerRepoMock) CreateEntries(entries ...*model.LedgerEntry) error {
+	args := m.Called(entries)
+	return args.Error(0)
+}
@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MerchantRepoMock struct {
+	mock.Mock
+}
+
+func (m *MerchantRepoMock) GetMerchantByID(merchantID uint) (*model.Merchant, error) {
+	args := m.Called(merchantID)
+	return args.Get(0).(*model.Merchant), args.Error(1)
+}
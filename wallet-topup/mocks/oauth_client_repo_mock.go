@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OAuthClientRepoMock struct {
+	mock.Mock
+}
+
+func (m *OAuthClientRepoMock) GetClient(clientID string) (*model.OAuthClient, error) {
+	args := m.Called(clientID)
+	return args.Get(0).(*model.OAuthClient), args.Error(1)
+}
+
+func (m *OAuthClientRepoMock) CreateClient(c *model.OAuthClient) (bool, error) {
+	args := m.Called(c)
+	return args.Bool(0), args.Error(1)
+}
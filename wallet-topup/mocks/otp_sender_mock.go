@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OTPSenderMock struct {
+	mock.Mock
+}
+
+func (m *OTPSenderMock) SendOTP(ctx context.Context, channel, destination, code string) error {
+	args := m.Called(ctx, channel, destination, code)
+	return args.Error(0)
+}
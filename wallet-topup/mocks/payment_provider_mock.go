@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentProviderMock struct {
+	mock.Mock
+}
+
+func (m *PaymentProviderMock) Charge(ctx context.Context, userID uint, amount float64, method, token, reference string) (string, error) {
+	args := m.Called(ctx, userID, amount, method, token, reference)
+	return args.String(0), args.Error(1)
+}
+
+func (m *PaymentProviderMock) Refund(ctx context.Context, reference string) error {
+	args := m.Called(ctx, reference)
+	return args.Error(0)
+}
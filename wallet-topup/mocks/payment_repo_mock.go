@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentRepoMock struct {
+	mock.Mock
+}
+
+func (m *PaymentRepoMock) CreatePayment(payment *model.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *PaymentRepoMock) GetPaymentByID(paymentID string) (*model.Payment, error) {
+	args := m.Called(paymentID)
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *PaymentRepoMock) GetPaymentByReference(merchantID uint, orderReference string) (*model.Payment, error) {
+	args := m.Called(merchantID, orderReference)
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *PaymentRepoMock) GetPaymentByIDForUpdate(paymentID string) (*model.Payment, error) {
+	args := m.Called(paymentID)
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *PaymentRepoMock) UpdatePaymentRefund(paymentID string, refundedAmount float64, status string) error {
+	args := m.Called(paymentID, refundedAmount, status)
+	return args.Error(0)
+}
@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"context"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PayoutProviderMock struct {
+	mock.Mock
+}
+
+func (m *PayoutProviderMock) SubmitPayout(ctx context.Context, withdrawal *model.Withdrawal) (string, error) {
+	args := m.Called(ctx, withdrawal)
+	return args.String(0), args.Error(1)
+}
+
+func (m *PayoutProviderMock) GetPayoutStatus(ctx context.Context, reference string) (string, string, error) {
+	args := m.Called(ctx, reference)
+	return args.String(0), args.String(1), args.Error(2)
+}
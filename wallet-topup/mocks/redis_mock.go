@@ -24,8 +24,26 @@ func (m *RedisMock) Set(ctx context.Context, key string, value interface{}, expi
 	return redis.NewStatusResult("", args.Error(0))
 }
 
+func (m *RedisMock) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	args := m.Called(ctx, key, value, expiration)
+
+	return redis.NewBoolResult(args.Bool(0), args.Error(1))
+}
+
 func (m *RedisMock) Del(ctx context.Context, keys ...string) *redis.IntCmd {
 	args := m.Called(ctx, keys)
 
 	return redis.NewIntResult(1, args.Error(0))
 }
+
+func (m *RedisMock) Incr(ctx context.Context, key string) *redis.IntCmd {
+	args := m.Called(ctx, key)
+
+	return redis.NewIntResult(int64(args.Int(0)), args.Error(1))
+}
+
+func (m *RedisMock) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	args := m.Called(ctx, key, expiration)
+
+	return redis.NewBoolResult(true, args.Error(0))
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduleRepoMock struct {
+	mock.Mock
+}
+
+func (m *ScheduleRepoMock) CreateSchedule(schedule *model.TopUpSchedule) error {
+	args := m.Called(schedule)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepoMock) GetScheduleByID(scheduleID string) (*model.TopUpSchedule, error) {
+	args := m.Called(scheduleID)
+	return args.Get(0).(*model.TopUpSchedule), args.Error(1)
+}
+
+func (m *ScheduleRepoMock) UpdateScheduleProgress(schedule *model.TopUpSchedule) error {
+	args := m.Called(schedule)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepoMock) UpdateScheduleStatus(scheduleID, fromStatus, toStatus string, nextRunAt time.Time) error {
+	args := m.Called(scheduleID, fromStatus, toStatus, nextRunAt)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepoMock) ListDueSchedules(now time.Time, limit int) ([]model.TopUpSchedule, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]model.TopUpSchedule), args.Error(1)
+}
+
+func (m *ScheduleRepoMock) ClaimSchedule(scheduleID string, expected, leaseUntil time.Time) error {
+	args := m.Called(scheduleID, expected, leaseUntil)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepoMock) CreateRun(run *model.ScheduleRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *ScheduleRepoMock) ListRuns(scheduleID string, limit int) ([]model.ScheduleRun, error) {
+	args := m.Called(scheduleID, limit)
+	return args.Get(0).([]model.ScheduleRun), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenIssuerMock struct {
+	mock.Mock
+}
+
+func (m *TokenIssuerMock) IssueToken(subject string, roles []string, userID *uint, sessionID string) (*model.AccessToken, error) {
+	args := m.Called(subject, roles, userID, sessionID)
+	return args.Get(0).(*model.AccessToken), args.Error(1)
+}
+
+func (m *TokenIssuerMock) IssueClientToken(clientID string, scopes []string) (*model.AccessToken, error) {
+	args := m.Called(clientID, scopes)
+	return args.Get(0).(*model.AccessToken), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"time"
 	"wallet-topup/model"
 
 	"github.com/stretchr/testify/mock"
@@ -20,7 +21,17 @@ func (m *TransactionRepoMock) GetTransactionByID(transactionID string) (*model.T
 	return args.Get(0).(*model.Transaction), args.Error(1)
 }
 
-func (m *TransactionRepoMock) UpdateTransactionStatus(transactionID string, status string) error {
-	args := m.Called(transactionID, status)
+func (m *TransactionRepoMock) CompleteTransaction(transactionID string) error {
+	args := m.Called(transactionID)
+	return args.Error(0)
+}
+
+func (m *TransactionRepoMock) SumTopUpsSince(userID uint, since time.Time) (float64, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *TransactionRepoMock) ExtendTransaction(transactionID string, expiresAt time.Time) error {
+	args := m.Called(transactionID, expiresAt)
 	return args.Error(0)
 }
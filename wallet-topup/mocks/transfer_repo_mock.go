@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TransferRepoMock struct {
+	mock.Mock
+}
+
+func (m *TransferRepoMock) CreateTransfer(transfer *model.Transfer) error {
+	args := m.Called(transfer)
+	return args.Error(0)
+}
+
+func (m *TransferRepoMock) GetTransferByID(transferID string) (*model.Transfer, error) {
+	args := m.Called(transferID)
+	return args.Get(0).(*model.Transfer), args.Error(1)
+}
+
+func (m *TransferRepoMock) SumOutgoingSince(userID uint, since time.Time) (float64, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).(float64), args.Error(1)
+}
@@ -0,0 +1,13 @@
+package mocks
+
+import "wallet-topup/model"
+
+// TxManagerMock runs the callback directly against the configured repository
+// mocks without opening a real transaction.
+type TxManagerMock struct {
+	Repos model.TxRepositories
+}
+
+func (m *TxManagerMock) WithinTransaction(fn func(repos model.TxRepositories) error) error {
+	return fn(m.Repos)
+}
@@ -19,3 +19,38 @@ func (m *UserRepoMock) UpdateUserBalance(userID uint, amount float64) error {
 	args := m.Called(userID, amount)
 	return args.Error(0)
 }
+
+func (m *UserRepoMock) GetUserByIDForUpdate(userID uint) (*model.User, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *UserRepoMock) DebitUserBalance(userID uint, amount float64) error {
+	args := m.Called(userID, amount)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) UpdateUserStatus(userID uint, fromStatus, status string) error {
+	args := m.Called(userID, fromStatus, status)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) UpdateUserTier(userID uint, tier string) error {
+	args := m.Called(userID, tier)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) CreateUser(user *model.User) (bool, error) {
+	args := m.Called(user)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *UserRepoMock) GetUserByExternalRef(externalRef string) (*model.User, error) {
+	args := m.Called(externalRef)
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *UserRepoMock) UpdateUserProfile(user *model.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
@@ -34,3 +34,35 @@ func (m *WalletServiceMock) ConfirmTransaction(ctx context.Context, transactionI
 	}
 	return nil, args.Error(1)
 }
+
+func (m *WalletServiceMock) ConfirmWithCode(ctx context.Context, transactionID, code string) (*model.Transaction, error) {
+	args := m.Called(ctx, transactionID, code)
+	if txn := args.Get(0); txn != nil {
+		return txn.(*model.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WalletServiceMock) QuoteTopUp(ctx context.Context, userID uint, amount float64, method string) (*model.Quote, error) {
+	args := m.Called(ctx, userID, amount, method)
+	if quote := args.Get(0); quote != nil {
+		return quote.(*model.Quote), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WalletServiceMock) VerifyQuote(ctx context.Context, quoteToken string) (*model.Transaction, error) {
+	args := m.Called(ctx, quoteToken)
+	if txn := args.Get(0); txn != nil {
+		return txn.(*model.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WalletServiceMock) ExtendTransaction(ctx context.Context, transactionID string) (*model.Transaction, error) {
+	args := m.Called(ctx, transactionID)
+	if txn := args.Get(0); txn != nil {
+		return txn.(*model.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WalletStatusRepoMock struct {
+	mock.Mock
+}
+
+func (m *WalletStatusRepoMock) CreateStatusChange(change *model.WalletStatusChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *WalletStatusRepoMock) ListStatusChanges(userID uint) ([]model.WalletStatusChange, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.WalletStatusChange), args.Error(1)
+}
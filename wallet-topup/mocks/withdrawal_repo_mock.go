@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WithdrawalRepoMock struct {
+	mock.Mock
+}
+
+func (m *WithdrawalRepoMock) CreateWithdrawal(withdrawal *model.Withdrawal) error {
+	args := m.Called(withdrawal)
+	return args.Error(0)
+}
+
+func (m *WithdrawalRepoMock) GetWithdrawalByID(withdrawalID string) (*model.Withdrawal, error) {
+	args := m.Called(withdrawalID)
+	return args.Get(0).(*model.Withdrawal), args.Error(1)
+}
+
+func (m *WithdrawalRepoMock) UpdateWithdrawal(withdrawal *model.Withdrawal, fromStatus string) error {
+	args := m.Called(withdrawal, fromStatus)
+	return args.Error(0)
+}
+
+func (m *WithdrawalRepoMock) SumActiveSince(userID uint, since time.Time) (float64, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).(float64), args.Error(1)
+}
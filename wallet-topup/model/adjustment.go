@@ -0,0 +1,57 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AdjustmentCredit = "credit"
+	AdjustmentDebit  = "debit"
+
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+	AdjustmentRejected = "rejected"
+)
+
+var AdjustmentReasonCodes = []string{
+	"error_correction",
+	"goodwill",
+	"chargeback",
+	"fraud_recovery",
+	"other",
+}
+
+// Adjustment is a manual balance correction. It is proposed by one admin and
+// only posted to the wallet once a different admin approves it.
+type Adjustment struct {
+	AdjustmentID string `gorm:"primaryKey;type:uuid"`
+	UserID       uint
+	Direction    string
+	Amount       float64 `gorm:"type:numeric(12,2)"`
+	ReasonCode   string
+	Evidence     string
+	Status       string
+	ProposedBy   string
+	ReviewedBy   string
+	ReviewNote   string
+	CreatedAt    time.Time
+	ReviewedAt   *time.Time
+}
+
+type AdjustmentRepository interface {
+	CreateAdjustment(adjustment *Adjustment) error
+	GetAdjustmentByID(adjustmentID string) (*Adjustment, error)
+	ListAdjustments(status string, limit int) ([]Adjustment, error)
+	// UpdateAdjustmentReview saves the review outcome only if the adjustment
+	// is still pending.
+	UpdateAdjustmentReview(adjustment *Adjustment) error
+}
+
+type AdjustmentService interface {
+	ProposeAdjustment(ctx context.Context, actor string, adjustment *Adjustment) (*Adjustment, error)
+	ApproveAdjustment(ctx context.Context, actor, adjustmentID, note string) (*Adjustment, error)
+	RejectAdjustment(ctx context.Context, actor, adjustmentID, note string) (*Adjustment, error)
+	GetAdjustment(ctx context.Context, adjustmentID string) (*Adjustment, []AuditLog, error)
+	ListAdjustments(ctx context.Context, status string) ([]Adjustment, error)
+}
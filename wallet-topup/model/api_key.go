@@ -0,0 +1,73 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	APIKeyActive  = "active"
+	APIKeyRevoked = "revoked"
+)
+
+var (
+	ErrInvalidSignature = sentinel(KindUnauthorized, "invalid_signature", "invalid API key or signature")
+	ErrStaleRequest     = sentinel(KindUnauthorized, "stale_request", "request timestamp is outside the allowed window")
+	ErrReplayedRequest  = sentinel(KindUnauthorized, "replayed_request", "request nonce has already been used")
+)
+
+// APIKey lets a merchant's backend call the API by signing requests with its
+// secret. The secret is needed to check signatures, so it is stored
+// encrypted with a server-side key that is not kept in the database. A
+// rotated key keeps working until ExpiresAt so the merchant can switch over.
+type APIKey struct {
+	KeyID           string `gorm:"primaryKey"`
+	MerchantID      uint
+	EncryptedSecret string
+	Status          string
+	CreatedBy       string
+	CreatedAt       time.Time
+	ExpiresAt       *time.Time
+	RevokedAt       *time.Time
+}
+
+// Usable reports whether the key may sign requests at now.
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.Status == APIKeyActive && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// IssuedAPIKey is a new key together with its secret, which is shown once.
+type IssuedAPIKey struct {
+	Key    *APIKey
+	Secret string
+}
+
+// SignedRequest is what the API key middleware read from a request.
+// BodyHash is the hex SHA-256 of the body.
+type SignedRequest struct {
+	KeyID     string
+	Method    string
+	Path      string
+	Timestamp string
+	Nonce     string
+	BodyHash  string
+	Signature string
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *APIKey) error
+	GetAPIKey(keyID string) (*APIKey, error)
+	ListAPIKeys(merchantID uint) ([]APIKey, error)
+	// ExpireAPIKey and RevokeAPIKey only change active keys and return
+	// ErrStatusChanged otherwise.
+	ExpireAPIKey(keyID string, at time.Time) error
+	RevokeAPIKey(keyID string, at time.Time) error
+}
+
+type APIKeyService interface {
+	IssueKey(ctx context.Context, actor string, merchantID uint) (*IssuedAPIKey, error)
+	RotateKey(ctx context.Context, actor, keyID string) (*IssuedAPIKey, error)
+	RevokeKey(ctx context.Context, actor, keyID string) (*APIKey, error)
+	ListKeys(ctx context.Context, merchantID uint) ([]APIKey, error)
+	Authenticate(ctx context.Context, req SignedRequest) (*APIKey, error)
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// AuditLog records who did what to which entity. Details holds a JSON object
+// with action-specific data.
+type AuditLog struct {
+	AuditID    string `gorm:"primaryKey;type:uuid"`
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	Details    string `gorm:"type:jsonb"`
+	CreatedAt  time.Time
+}
+
+type AuditRepository interface {
+	CreateAuditLog(entry *AuditLog) error
+	ListAuditLogs(entityType, entityID string) ([]AuditLog, error)
+}
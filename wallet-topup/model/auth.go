@@ -0,0 +1,96 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+	RoleService  = "service"
+	// RoleMerchant is given to requests signed with a merchant API key.
+	RoleMerchant = "merchant"
+	// RoleSystem is held only by background jobs, never by a token.
+	RoleSystem = "system"
+)
+
+var (
+	ErrInvalidCredentials  = sentinel(KindUnauthorized, "invalid_credentials", "invalid username or password")
+	ErrAccountLocked       = sentinel(KindRateLimited, "account_locked", "too many failed login attempts; try again later")
+	ErrInvalidRefreshToken = sentinel(KindUnauthorized, "invalid_refresh_token", "invalid or expired refresh token")
+	ErrRefreshTokenReused  = sentinel(KindUnauthorized, "refresh_token_reused", "refresh token was already used; the session has been revoked")
+
+	ErrMissingToken = sentinel(KindUnauthorized, "missing_token", "missing or invalid token")
+	ErrInvalidToken = sentinel(KindUnauthorized, "invalid_token", "invalid token")
+	ErrTokenRevoked = sentinel(KindUnauthorized, "token_revoked", "token has been revoked")
+)
+
+// Credential is a login for the API. Customers are linked to their wallet
+// through UserID; staff and service logins have no UserID. Roles is a
+// comma-separated list.
+type Credential struct {
+	Username     string `gorm:"primaryKey"`
+	PasswordHash string
+	UserID       *uint
+	Roles        string
+	Disabled     bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (c *Credential) RoleList() []string {
+	if c.Roles == "" {
+		return nil
+	}
+	return strings.Split(c.Roles, ",")
+}
+
+type CredentialRepository interface {
+	GetCredential(username string) (*Credential, error)
+	// CreateCredential inserts c unless the username is taken, reporting
+	// whether it was created.
+	CreateCredential(c *Credential) (bool, error)
+}
+
+// AccessToken is a signed access token with the ID (jti) and expiry it was
+// signed with, so it can be revoked later.
+type AccessToken struct {
+	Token     string
+	ID        string
+	ExpiresAt time.Time
+}
+
+// TokenPair is handed out at login and on every refresh. The refresh token
+// can be used once; each use returns a new pair.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// TokenIssuer signs access tokens for an authenticated subject. sessionID
+// ties the token to the login session it was issued for, if any.
+type TokenIssuer interface {
+	IssueToken(subject string, roles []string, userID *uint, sessionID string) (*AccessToken, error)
+	// IssueClientToken signs a token for an OAuth client that is limited to
+	// scopes, whatever its role would otherwise allow.
+	IssueClientToken(clientID string, scopes []string) (*AccessToken, error)
+}
+
+// TokenRevocations reports whether the token a principal presented has been
+// revoked by a logout.
+type TokenRevocations interface {
+	IsRevoked(ctx context.Context, p *Principal) (bool, error)
+}
+
+type AuthService interface {
+	Login(ctx context.Context, username, password string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout ends the caller's session; LogoutAll ends every session of the
+	// caller's subject.
+	Logout(ctx context.Context) error
+	LogoutAll(ctx context.Context) error
+	CreateCredential(ctx context.Context, username, password string, userID *uint, roles []string) (*Credential, error)
+}
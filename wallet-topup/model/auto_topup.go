@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// AutoTopUpConfig refills a wallet with Amount from a saved payment method
+// whenever a debit leaves the balance below Threshold, up to DailyLimit per day.
+type AutoTopUpConfig struct {
+	UserID        uint    `gorm:"primaryKey"`
+	Threshold     float64 `gorm:"type:numeric(12,2)"`
+	Amount        float64 `gorm:"type:numeric(12,2)"`
+	DailyLimit    float64 `gorm:"type:numeric(12,2)"`
+	PaymentMethod string
+	PaymentToken  string
+	Enabled       bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type AutoTopUp struct {
+	AutoTopUpID   string `gorm:"primaryKey;type:uuid"`
+	UserID        uint
+	Amount        float64 `gorm:"type:numeric(12,2)"`
+	Status        string
+	TransactionID string
+	Error         string
+	CreatedAt     time.Time
+}
+
+type AutoTopUpRepository interface {
+	GetConfig(userID uint) (*AutoTopUpConfig, error)
+	SaveConfig(cfg *AutoTopUpConfig) error
+	CreateAutoTopUp(topUp *AutoTopUp) error
+	SumSucceededSince(userID uint, since time.Time) (float64, error)
+}
+
+// BalanceObserver is notified after a committed debit lowers a wallet balance.
+type BalanceObserver interface {
+	BalanceDecreased(userID uint)
+}
+
+type AutoTopUpService interface {
+	BalanceObserver
+	SaveConfig(ctx context.Context, cfg *AutoTopUpConfig) (*AutoTopUpConfig, error)
+	GetConfig(ctx context.Context, userID uint) (*AutoTopUpConfig, error)
+	CheckBalance(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	BatchPending             = "pending"
+	BatchProcessing          = "processing"
+	BatchCompleted           = "completed"
+	BatchCompletedWithErrors = "completed_with_errors"
+
+	BatchRowPending  = "pending"
+	BatchRowCredited = "credited"
+	BatchRowFailed   = "failed"
+)
+
+// Batch is a bulk top-up submitted as a CSV file. Reference is supplied by the
+// client and is unique, so resubmitting the same batch never credits twice.
+type Batch struct {
+	BatchID       string `gorm:"primaryKey;type:uuid"`
+	Reference     string
+	Status        string
+	TotalRows     int
+	SucceededRows int
+	FailedRows    int
+	TotalAmount   float64 `gorm:"type:numeric(14,2)"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+}
+
+type BatchRow struct {
+	BatchID   string `gorm:"primaryKey;type:uuid"`
+	RowNumber int    `gorm:"primaryKey"`
+	UserID    uint
+	Amount    float64 `gorm:"type:numeric(12,2)"`
+	Reference string
+	Status    string
+	Error     string
+	UpdatedAt time.Time
+}
+
+type BatchRowError struct {
+	RowNumber int    `json:"row"`
+	Error     string `json:"error"`
+}
+
+// BatchValidationError lists every CSV row that failed validation. No part of
+// a batch is accepted when it is returned.
+type BatchValidationError struct {
+	Rows []BatchRowError
+}
+
+func (e *BatchValidationError) Error() string {
+	return fmt.Sprintf("batch has %d invalid rows", len(e.Rows))
+}
+
+type BatchRepository interface {
+	// CreateBatch stores the batch and all of its rows atomically.
+	CreateBatch(batch *Batch, rows []BatchRow) error
+	GetBatchByID(batchID string) (*Batch, error)
+	GetBatchByReference(reference string) (*Batch, error)
+	ListRows(batchID string) ([]BatchRow, error)
+	ListPendingRows(batchID string, limit int) ([]BatchRow, error)
+	// UpdateRowStatus moves a row to status only if it is still in fromStatus.
+	UpdateRowStatus(batchID string, rowNumber int, fromStatus, status, errMsg string) error
+	// RefreshBatchProgress recomputes the batch counters and status from its rows.
+	RefreshBatchProgress(batchID string) (*Batch, error)
+	ListUnfinishedBatches(limit int) ([]Batch, error)
+}
+
+type BatchService interface {
+	SubmitBatch(ctx context.Context, reference string, csv io.Reader) (batch *Batch, created bool, err error)
+	GetBatch(ctx context.Context, batchID string) (*Batch, error)
+	ListRows(ctx context.Context, batchID string) ([]BatchRow, error)
+	ProcessBatch(ctx context.Context, batchID string) error
+	ResumeUnfinishedBatches(ctx context.Context) error
+}
@@ -0,0 +1,117 @@
+package model
+
+import "errors"
+
+// ErrorKind says what went wrong with a request, independent of transport.
+// The handler package turns each kind into an HTTP status.
+type ErrorKind string
+
+const (
+	KindInvalid          ErrorKind = "invalid_request"
+	KindUnauthorized     ErrorKind = "unauthorized"
+	KindForbidden        ErrorKind = "forbidden"
+	KindNotFound         ErrorKind = "not_found"
+	KindConflict         ErrorKind = "conflict"
+	KindAlreadyCompleted ErrorKind = "already_completed"
+	KindExpired          ErrorKind = "expired"
+	KindLimitExceeded    ErrorKind = "limit_exceeded"
+	KindRateLimited      ErrorKind = "rate_limited"
+	KindUnavailable      ErrorKind = "unavailable"
+	KindTooLarge         ErrorKind = "payload_too_large"
+)
+
+// Error is a domain error. Code is stable and safe for clients to switch on;
+// it defaults to the kind and is narrowed for errors clients commonly need to
+// tell apart, such as wallet_frozen. Message is for people and may change.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Details map[string]any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns a copy of e with details attached, leaving shared
+// sentinel errors untouched.
+func (e *Error) WithDetails(details map[string]any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+func newError(kind ErrorKind, message string) *Error {
+	return &Error{Kind: kind, Code: string(kind), Message: message}
+}
+
+func sentinel(kind ErrorKind, code, message string) error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Invalid(message string) *Error {
+	return newError(KindInvalid, message)
+}
+
+func NotFound(message string) *Error {
+	return newError(KindNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return newError(KindConflict, message)
+}
+
+func AlreadyCompleted(message string) *Error {
+	return newError(KindAlreadyCompleted, message)
+}
+
+func Expired(message string) *Error {
+	return newError(KindExpired, message)
+}
+
+func LimitExceeded(message string) *Error {
+	return newError(KindLimitExceeded, message)
+}
+
+func TooLarge(message string) *Error {
+	return newError(KindTooLarge, message)
+}
+
+func Unauthorized(message string) *Error {
+	return newError(KindUnauthorized, message)
+}
+
+// Unavailable reports that a dependency such as the database, Redis or a
+// provider failed. cause is kept for logs and errors.Is but never shown to
+// clients.
+func Unavailable(message string, cause error) *Error {
+	e := newError(KindUnavailable, message)
+	e.Err = cause
+	return e
+}
+
+// KindOf returns the kind of the first *Error in err's chain, or "" when err
+// is not a domain error.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return ""
+}
+
+var (
+	ErrInsufficientFunds = sentinel(KindLimitExceeded, "insufficient_funds", "insufficient funds")
+	ErrStatusChanged     = sentinel(KindConflict, "status_changed", "status was changed by another request")
+
+	ErrWalletFrozen    = sentinel(KindConflict, "wallet_frozen", "wallet is frozen")
+	ErrWalletSuspended = sentinel(KindConflict, "wallet_suspended", "wallet is suspended")
+	ErrWalletClosed    = sentinel(KindConflict, "wallet_closed", "wallet is closed")
+	ErrWalletInactive  = sentinel(KindConflict, "wallet_inactive", "wallet is not active")
+)
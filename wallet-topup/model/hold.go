@@ -0,0 +1,71 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// HoldReasonWithdrawal marks the hold a withdrawal places on its funds. Only
+// the withdrawal may capture or release it.
+const HoldReasonWithdrawal = "withdrawal"
+
+// HoldReasonPayment marks a payment authorization: a hold the customer places
+// for one merchant, which only that merchant's payment may capture.
+const HoldReasonPayment = "payment_authorization"
+
+// Hold reserves part of a wallet balance without spending it. Active holds are
+// subtracted from the available balance until they are captured, released or
+// expire. A nil ExpiresAt means the hold never expires on its own. MerchantID
+// is set on payment authorizations.
+type Hold struct {
+	HoldID     string `gorm:"primaryKey;type:uuid"`
+	UserID     uint
+	MerchantID *uint
+	Amount     float64 `gorm:"type:numeric(12,2)"`
+	Reason     string
+	Status     string
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (h *Hold) ForWithdrawal() bool {
+	return h.Reason == HoldReasonWithdrawal
+}
+
+func (h *Hold) ForPayment() bool {
+	return h.Reason == HoldReasonPayment
+}
+
+type Balance struct {
+	UserID    uint
+	Balance   float64
+	Held      float64
+	Available float64
+	Holds     []Hold
+}
+
+type HoldRepository interface {
+	CreateHold(hold *Hold) error
+	GetHoldByID(holdID string) (*Hold, error)
+	// UpdateHoldStatus moves a hold to toStatus only if it is still in fromStatus.
+	UpdateHoldStatus(holdID, fromStatus, toStatus string) error
+	SumActiveHolds(userID uint) (float64, error)
+	ListActiveHolds(userID uint) ([]Hold, error)
+	ListExpiredHolds(now time.Time, limit int) ([]Hold, error)
+}
+
+type HoldService interface {
+	PlaceHold(ctx context.Context, userID uint, amount float64, reason string, ttl time.Duration) (*Hold, error)
+	CaptureHold(ctx context.Context, holdID string) (*Hold, error)
+	ReleaseHold(ctx context.Context, holdID string) (*Hold, error)
+	GetBalance(ctx context.Context, userID uint) (*Balance, error)
+	ReleaseExpiredHolds(ctx context.Context) (int, error)
+}
@@ -0,0 +1,59 @@
+package model
+
+import "context"
+
+const (
+	KYCBasic    = "basic"
+	KYCVerified = "verified"
+	KYCPremium  = "premium"
+)
+
+// KYCTier holds the limits and features granted at one level of customer
+// verification. Amounts are in THB.
+type KYCTier struct {
+	Name           string
+	MaxTransaction float64
+	DailyLimit     float64
+	MaxBalance     float64
+	Transfers      bool
+	Withdrawals    bool
+}
+
+var KYCTiers = map[string]KYCTier{
+	KYCBasic: {
+		Name:           KYCBasic,
+		MaxTransaction: 5000,
+		DailyLimit:     10000,
+		MaxBalance:     5000,
+	},
+	KYCVerified: {
+		Name:           KYCVerified,
+		MaxTransaction: 50000,
+		DailyLimit:     100000,
+		MaxBalance:     100000,
+		Transfers:      true,
+		Withdrawals:    true,
+	},
+	KYCPremium: {
+		Name:           KYCPremium,
+		MaxTransaction: 100000,
+		DailyLimit:     500000,
+		MaxBalance:     1000000,
+		Transfers:      true,
+		Withdrawals:    true,
+	},
+}
+
+// TierFor returns the limits for a tier name. Unknown or empty names get the
+// basic tier.
+func TierFor(name string) KYCTier {
+	if tier, ok := KYCTiers[name]; ok {
+		return tier
+	}
+	return KYCTiers[KYCBasic]
+}
+
+type KYCService interface {
+	ChangeTier(ctx context.Context, actor string, userID uint, tier, reason string) (*User, error)
+	GetKYC(ctx context.Context, userID uint) (*User, []AuditLog, error)
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// LedgerEntry records one side of a balance movement. Amount is negative for
+// debits and positive for credits; Reference links the entry to the operation
+// (e.g. a transfer ID) that produced it.
+type LedgerEntry struct {
+	EntryID   string `gorm:"primaryKey;type:uuid"`
+	UserID    uint
+	Amount    float64 `gorm:"type:numeric(12,2)"`
+	Type      string
+	Reference string
+	CreatedAt time.Time
+}
+
+type LedgerRepository interface {
+	CreateEntries(entries ...*LedgerEntry) error
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type Merchant struct {
+	MerchantID uint `gorm:"primaryKey"`
+	Name       string
+	Status     string
+	CreatedAt  time.Time
+}
+
+type MerchantRepository interface {
+	GetMerchantByID(merchantID uint) (*Merchant, error)
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PaymentCompleted         = "completed"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentRefunded          = "refunded"
+)
+
+// Payment is a merchant purchase paid from a wallet. OrderReference is the
+// merchant's own order ID and is unique per merchant.
+type Payment struct {
+	PaymentID      string `gorm:"primaryKey;type:uuid"`
+	MerchantID     uint
+	UserID         uint
+	Amount         float64 `gorm:"type:numeric(12,2)"`
+	RefundedAmount float64 `gorm:"type:numeric(12,2)"`
+	OrderReference string
+	Description    string
+	Status         string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type PaymentRepository interface {
+	CreatePayment(payment *Payment) error
+	GetPaymentByID(paymentID string) (*Payment, error)
+	GetPaymentByReference(merchantID uint, orderReference string) (*Payment, error)
+	GetPaymentByIDForUpdate(paymentID string) (*Payment, error)
+	UpdatePaymentRefund(paymentID string, refundedAmount float64, status string) error
+}
+
+var ErrPaymentAuthorizationRequired = sentinel(KindForbidden, "authorization_required", "merchants must capture a payment authorization from the customer")
+
+type PaymentService interface {
+	// AuthorizePayment reserves amount on the customer's wallet for one
+	// merchant until ttl passes. The merchant spends it by passing the
+	// returned hold's ID to CreatePayment.
+	AuthorizePayment(ctx context.Context, merchantID, userID uint, amount float64, ttl time.Duration) (*Hold, error)
+	// CreatePayment debits the wallet. authorizationID names a payment
+	// authorization to capture, and is required when a merchant key calls.
+	CreatePayment(ctx context.Context, merchantID, userID uint, amount float64, orderReference, description, authorizationID string) (*Payment, error)
+	GetPayment(ctx context.Context, merchantID uint, paymentID string) (*Payment, error)
+	RefundPayment(ctx context.Context, merchantID uint, paymentID string, amount float64) (*Payment, error)
+}
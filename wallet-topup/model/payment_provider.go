@@ -0,0 +1,12 @@
+package model
+
+import "context"
+
+// PaymentProvider charges a customer's saved payment method so the amount can
+// be credited to their wallet. Reference is the verified top-up transaction ID
+// and lets the provider de-duplicate retries.
+type PaymentProvider interface {
+	Charge(ctx context.Context, userID uint, amount float64, method, token, reference string) (chargeID string, err error)
+	// Refund returns the charge made for reference to the customer.
+	Refund(ctx context.Context, reference string) error
+}
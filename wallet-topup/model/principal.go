@@ -0,0 +1,78 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+var (
+	ErrForbidden        = sentinel(KindForbidden, "forbidden", "not allowed to act on this wallet")
+	ErrUnauthenticated  = sentinel(KindUnauthorized, "unauthenticated", "authentication required")
+	ErrMerchantMismatch = sentinel(KindForbidden, "merchant_mismatch", "merchant_id does not match the API key")
+	ErrQuoteRequired    = sentinel(KindForbidden, "quote_required", "merchants must verify with the customer's quote_token")
+)
+
+// Principal is the authenticated caller of a request, taken from its token.
+// Customers carry the UserID of their own wallet and merchant API keys the
+// MerchantID they were issued to. Scopes is set for OAuth
+// client tokens and then replaces whatever the roles would grant. TokenID,
+// SessionID and the token's lifetime are kept so the token can be revoked on
+// logout.
+type Principal struct {
+	Subject    string
+	Roles      []string
+	Scopes     []string
+	UserID     *uint
+	MerchantID *uint
+	TokenID    string
+	SessionID  string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Privileged reports whether the caller may act on wallets other than its own.
+// Merchants are not: they act only on their own payments and on what a
+// customer has authorized them to, such as a quote.
+func (p *Principal) Privileged() bool {
+	return p.HasRole(RoleAdmin) || p.HasRole(RoleService) || p.IsSystem()
+}
+
+// IsSystem reports whether the caller is a background job of the service
+// itself rather than a client.
+func (p *Principal) IsSystem() bool {
+	return p.HasRole(RoleSystem)
+}
+
+// IsMerchant reports whether the caller is a merchant API key rather than a
+// privileged caller that also names a merchant.
+func (p *Principal) IsMerchant() bool {
+	return p.MerchantID != nil && !p.Privileged()
+}
+
+func (p *Principal) Owns(userID uint) bool {
+	return p.UserID != nil && *p.UserID == userID
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// WithSystem marks ctx as a call made by the background job named job, such
+// as a scheduled top-up. Requests from clients never carry this principal.
+func WithSystem(ctx context.Context, job string) context.Context {
+	return WithPrincipal(ctx, &Principal{Subject: "system:" + job, Roles: []string{RoleSystem}})
+}
+
+// PrincipalFrom returns the caller stored by WithPrincipal. Callers with
+// none must be refused.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
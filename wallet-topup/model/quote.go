@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+var ErrQuoteTermsChanged = sentinel(KindConflict, "quote_terms_changed", "quoted fee or bonus is no longer available; request a new quote")
+
+// Quote previews the terms of a top-up without creating a transaction. Token
+// is a signed copy of the terms that VerifyQuote accepts until ExpiresAt.
+type Quote struct {
+	QuoteID        string
+	UserID         uint
+	Amount         float64
+	PaymentMethod  string
+	Fee            float64
+	Bonus          float64
+	LimitRemaining float64
+	ExpiresAt      time.Time
+	Token          string
+}
+
+// Charge is what the customer pays.
+func (q *Quote) Charge() float64 {
+	return q.Amount + q.Fee
+}
+
+// Credit is what lands in the wallet.
+func (q *Quote) Credit() float64 {
+	return q.Amount + q.Bonus
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ScheduleActive = "active"
+	SchedulePaused = "paused"
+
+	FailurePolicyRetry = "retry"
+	FailurePolicySkip  = "skip"
+
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunSkipped   = "skipped"
+)
+
+// TopUpSchedule tops up a wallet from a saved payment method whenever its cron
+// expression fires. PaymentToken is the provider's token for the saved method.
+type TopUpSchedule struct {
+	ScheduleID    string `gorm:"primaryKey;type:uuid"`
+	UserID        uint
+	Amount        float64 `gorm:"type:numeric(12,2)"`
+	PaymentMethod string
+	PaymentToken  string
+	CronExpr      string
+	FailurePolicy string
+	MaxRetries    int
+	RetryCount    int
+	Status        string
+	NextRunAt     time.Time
+	LastRunAt     *time.Time
+	// PendingTransactionID is a top-up that was charged but not credited.
+	// The next run confirms or refunds it before charging again.
+	PendingTransactionID string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+type ScheduleRun struct {
+	RunID         string `gorm:"primaryKey;type:uuid"`
+	ScheduleID    string
+	Attempt       int
+	Status        string
+	TransactionID string
+	Error         string
+	CreatedAt     time.Time
+}
+
+type ScheduleRepository interface {
+	CreateSchedule(schedule *TopUpSchedule) error
+	GetScheduleByID(scheduleID string) (*TopUpSchedule, error)
+	// UpdateScheduleProgress saves the outcome of a run: next run time,
+	// retry count and last run time.
+	UpdateScheduleProgress(schedule *TopUpSchedule) error
+	UpdateScheduleStatus(scheduleID, fromStatus, toStatus string, nextRunAt time.Time) error
+	ListDueSchedules(now time.Time, limit int) ([]TopUpSchedule, error)
+	// ClaimSchedule moves next_run_at from expected to leaseUntil so only one
+	// worker runs a due schedule. It returns ErrStatusChanged if another
+	// worker claimed it first.
+	ClaimSchedule(scheduleID string, expected, leaseUntil time.Time) error
+	CreateRun(run *ScheduleRun) error
+	ListRuns(scheduleID string, limit int) ([]ScheduleRun, error)
+}
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, schedule *TopUpSchedule) (*TopUpSchedule, error)
+	GetSchedule(ctx context.Context, scheduleID string) (*TopUpSchedule, error)
+	PauseSchedule(ctx context.Context, scheduleID string) (*TopUpSchedule, error)
+	ResumeSchedule(ctx context.Context, scheduleID string) (*TopUpSchedule, error)
+	ListRuns(ctx context.Context, scheduleID string) ([]ScheduleRun, error)
+	RunDueSchedules(ctx context.Context) (int, error)
+}
@@ -0,0 +1,18 @@
+package model
+
+import "strings"
+
+// ScopeAllows reports whether any of granted covers scope. A scope is
+// "resource:action"; "resource:*" covers every action on the resource and
+// "*" covers everything.
+func ScopeAllows(granted []string, scope string) bool {
+	for _, g := range granted {
+		if g == "*" || g == scope {
+			return true
+		}
+		if resource, ok := strings.CutSuffix(g, ":*"); ok && strings.HasPrefix(scope, resource+":") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	ErrInvalidOTP          = sentinel(KindInvalid, "invalid_otp", "invalid or expired one-time code")
+	ErrOTPAttemptsExceeded = sentinel(KindRateLimited, "otp_attempts_exceeded", "too many wrong one-time codes; request a new code")
+	ErrOTPRateLimited      = sentinel(KindRateLimited, "otp_rate_limited", "too many one-time codes requested for this transaction")
+)
+
+// ChallengeRequiredError is returned when confirming a top-up needs a
+// one-time code. A code has been sent to Destination (masked) and the confirm
+// must be repeated with it before ExpiresAt.
+type ChallengeRequiredError struct {
+	TransactionID string
+	Channel       string
+	Destination   string
+	ExpiresAt     time.Time
+}
+
+func (e *ChallengeRequiredError) Error() string {
+	return "one-time code required to confirm this transaction"
+}
+
+// OTPSender delivers a one-time code. channel is "sms" or "email".
+type OTPSender interface {
+	SendOTP(ctx context.Context, channel, destination, code string) error
+}
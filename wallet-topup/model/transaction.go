@@ -1,6 +1,23 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+// Payment methods a top-up can be paid with.
+const (
+	PaymentCreditCard   = "credit_card"
+	PaymentDebitCard    = "debit_card"
+	PaymentBankTransfer = "bank_transfer"
+	PaymentPromptPay    = "promptpay"
+)
+
+var PaymentMethods = []string{PaymentCreditCard, PaymentDebitCard, PaymentBankTransfer, PaymentPromptPay}
+
+func IsPaymentMethod(method string) bool {
+	return slices.Contains(PaymentMethods, method)
+}
 
 type Transaction struct {
 	TransactionID string `gorm:"primaryKey;type:uuid"`
@@ -9,10 +26,20 @@ type Transaction struct {
 	PaymentMethod string
 	Status        string
 	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	Extensions    int
 }
 
 type TransactionRepository interface {
 	CreateTransaction(txn *Transaction) error
 	GetTransactionByID(transactionID string) (*Transaction, error)
-	UpdateTransactionStatus(transactionID string, status string) error
+	// CompleteTransaction marks a transaction completed, but only while it is
+	// still verified and unexpired, so it can be credited only once.
+	CompleteTransaction(transactionID string) error
+	// ExtendTransaction moves ExpiresAt and counts the extension, but only
+	// while the transaction is still verified and unexpired.
+	ExtendTransaction(transactionID string, expiresAt time.Time) error
+	// SumTopUpsSince totals completed top-ups and still-open verified ones
+	// created at or after since.
+	SumTopUpsSince(userID uint, since time.Time) (float64, error)
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type Transfer struct {
+	TransferID string `gorm:"primaryKey;type:uuid"`
+	FromUserID uint
+	ToUserID   uint
+	Amount     float64 `gorm:"type:numeric(12,2)"`
+	Note       string
+	Status     string
+	CreatedAt  time.Time
+}
+
+type TransferRepository interface {
+	CreateTransfer(transfer *Transfer) error
+	GetTransferByID(transferID string) (*Transfer, error)
+	SumOutgoingSince(userID uint, since time.Time) (float64, error)
+}
@@ -0,0 +1,7 @@
+package model
+
+import "context"
+
+type TransferService interface {
+	CreateTransfer(ctx context.Context, fromUserID, toUserID uint, amount float64, note string) (*Transfer, error)
+}
@@ -0,0 +1,21 @@
+package model
+
+// TxRepositories groups the repositories that can take part in a single
+// database transaction.
+type TxRepositories struct {
+	Users         UserRepository
+	Transactions  TransactionRepository
+	Transfers     TransferRepository
+	Ledger        LedgerRepository
+	Withdrawals   WithdrawalRepository
+	Holds         HoldRepository
+	Payments      PaymentRepository
+	Batches       BatchRepository
+	Adjustments   AdjustmentRepository
+	Audit         AuditRepository
+	StatusChanges WalletStatusRepository
+}
+
+type TxManager interface {
+	WithinTransaction(fn func(repos TxRepositories) error) error
+}
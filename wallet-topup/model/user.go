@@ -1,11 +1,74 @@
 package model
 
+import (
+	"context"
+	"time"
+)
+
+const (
+	WalletActive    = "active"
+	WalletFrozen    = "frozen"
+	WalletSuspended = "suspended"
+	WalletClosed    = "closed"
+)
+
+// User is a wallet owner. ExternalRef is the caller's stable handle for the
+// owner, a normalized phone number or email address, and is unique.
 type User struct {
-	UserID  uint    `gorm:"primaryKey"`
-	Balance float64 `gorm:"type:numeric(12,2)"`
+	UserID      uint    `gorm:"primaryKey"`
+	Balance     float64 `gorm:"type:numeric(12,2)"`
+	Status      string
+	KYCTier     string `gorm:"column:kyc_tier"`
+	ExternalRef string
+	FullName    string
+	Email       string
+	Phone       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ProfileUpdate lists the profile fields to change; nil fields are left as
+// they are.
+type ProfileUpdate struct {
+	FullName *string
+	Email    *string
+	Phone    *string
+}
+
+// CheckActive returns the error matching the wallet's status, or nil if the
+// wallet may move money. An empty status is treated as active.
+func (u *User) CheckActive() error {
+	switch u.Status {
+	case WalletActive, "":
+		return nil
+	case WalletFrozen:
+		return ErrWalletFrozen
+	case WalletSuspended:
+		return ErrWalletSuspended
+	case WalletClosed:
+		return ErrWalletClosed
+	}
+	return ErrWalletInactive
 }
 
 type UserRepository interface {
+	// CreateUser inserts user unless one with the same ExternalRef exists, in
+	// which case it reports created=false and leaves user untouched.
+	CreateUser(user *User) (created bool, err error)
 	GetUserByID(userID uint) (*User, error)
+	GetUserByExternalRef(externalRef string) (*User, error)
+	GetUserByIDForUpdate(userID uint) (*User, error)
 	UpdateUserBalance(userID uint, amount float64) error
+	DebitUserBalance(userID uint, amount float64) error
+	// UpdateUserStatus changes the wallet status only if it is still fromStatus.
+	UpdateUserStatus(userID uint, fromStatus, status string) error
+	UpdateUserTier(userID uint, tier string) error
+	UpdateUserProfile(user *User) error
+}
+
+type UserService interface {
+	CreateUser(ctx context.Context, user *User) (*User, bool, error)
+	GetUser(ctx context.Context, userID uint) (*User, error)
+	FindByExternalRef(ctx context.Context, externalRef string) (*User, error)
+	UpdateProfile(ctx context.Context, userID uint, update ProfileUpdate) (*User, error)
 }
@@ -7,7 +7,13 @@ import (
 type WalletService interface {
 	GetUserByID(userID uint) (*User, error)
 	VerifyTransaction(ctx context.Context, userID uint, amount float64, method string) (*Transaction, error)
+	QuoteTopUp(ctx context.Context, userID uint, amount float64, method string) (*Quote, error)
+	VerifyQuote(ctx context.Context, quoteToken string) (*Transaction, error)
 	ConfirmTransaction(ctx context.Context, transactionID string) (*Transaction, error)
+	// ConfirmWithCode confirms a transaction that needed step-up
+	// verification, using the one-time code sent for it.
+	ConfirmWithCode(ctx context.Context, transactionID, code string) (*Transaction, error)
+	ExtendTransaction(ctx context.Context, transactionID string) (*Transaction, error)
 }
 
 type Logger interface {
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// WalletStatusChange is one entry in a wallet's status history.
+type WalletStatusChange struct {
+	ChangeID   string `gorm:"primaryKey;type:uuid"`
+	UserID     uint
+	FromStatus string
+	ToStatus   string
+	Reason     string
+	Actor      string
+	CreatedAt  time.Time
+}
+
+type WalletStatusRepository interface {
+	CreateStatusChange(change *WalletStatusChange) error
+	ListStatusChanges(userID uint) ([]WalletStatusChange, error)
+}
+
+type WalletStatusService interface {
+	ChangeStatus(ctx context.Context, actor string, userID uint, status, reason string) (*User, error)
+	GetStatusHistory(ctx context.Context, userID uint) ([]WalletStatusChange, error)
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	WithdrawalRequested = "requested"
+	WithdrawalHeld      = "held"
+	WithdrawalSubmitted = "submitted"
+	WithdrawalPaid      = "paid"
+	WithdrawalFailed    = "failed"
+)
+
+type Withdrawal struct {
+	WithdrawalID    string `gorm:"primaryKey;type:uuid"`
+	UserID          uint
+	Amount          float64 `gorm:"type:numeric(12,2)"`
+	BankAccount     string
+	Status          string
+	HoldID          string
+	PayoutReference string
+	FailureReason   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type WithdrawalRepository interface {
+	CreateWithdrawal(withdrawal *Withdrawal) error
+	GetWithdrawalByID(withdrawalID string) (*Withdrawal, error)
+	// UpdateWithdrawal persists the status, hold, payout reference and failure
+	// reason of withdrawal, but only if its stored status is still fromStatus.
+	UpdateWithdrawal(withdrawal *Withdrawal, fromStatus string) error
+	SumActiveSince(userID uint, since time.Time) (float64, error)
+}
+
+// PayoutProvider sends money from the platform to a customer's bank account.
+type PayoutProvider interface {
+	SubmitPayout(ctx context.Context, withdrawal *Withdrawal) (reference string, err error)
+	// GetPayoutStatus returns one of PayoutPending, PayoutPaid or PayoutFailed
+	// along with a failure reason when the payout failed.
+	GetPayoutStatus(ctx context.Context, reference string) (status string, reason string, err error)
+}
+
+const (
+	PayoutPending = "pending"
+	PayoutPaid    = "paid"
+	PayoutFailed  = "failed"
+)
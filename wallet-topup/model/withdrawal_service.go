@@ -0,0 +1,9 @@
+package model
+
+import "context"
+
+type WithdrawalService interface {
+	RequestWithdrawal(ctx context.Context, userID uint, amount float64, bankAccount string) (*Withdrawal, error)
+	GetWithdrawal(ctx context.Context, withdrawalID string) (*Withdrawal, error)
+	RefreshWithdrawal(ctx context.Context, withdrawalID string) (*Withdrawal, error)
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"wallet-topup/model"
+)
+
+// LogOTPSender is a local stand-in for an SMS/email gateway. It writes each
+// code to the application log and, when path is set, appends it to that file
+// so it can be read back in development and tests. Never use it in
+// production: the codes end up in plain text.
+type LogOTPSender struct {
+	logger model.Logger
+	path   string
+	mu     sync.Mutex
+}
+
+func NewLogOTPSender(logger model.Logger, path string) *LogOTPSender {
+	return &LogOTPSender{logger: logger, path: path}
+}
+
+func (s *LogOTPSender) SendOTP(ctx context.Context, channel, destination, code string) error {
+	s.logger.Infof("[otp stand-in] %s to %s: %s", channel, destination, code)
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s %s %s %s\n", time.Now().Format(time.RFC3339), channel, destination, code)
+	return err
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakePaymentProvider is a local stand-in for a card/bank charging gateway.
+// Every charge succeeds except for tokens starting with "tok_fail", which are
+// declined. Charges are de-duplicated by reference.
+type FakePaymentProvider struct {
+	mu      sync.Mutex
+	charges map[string]string
+}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{charges: make(map[string]string)}
+}
+
+func (p *FakePaymentProvider) Charge(ctx context.Context, userID uint, amount float64, method, token, reference string) (string, error) {
+	if token == "" {
+		return "", errors.New("payment token is required")
+	}
+	if strings.HasPrefix(token, "tok_fail") {
+		return "", errors.New("payment declined")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id, ok := p.charges[reference]; ok {
+		return id, nil
+	}
+	id := "charge_" + uuid.New().String()
+	p.charges[reference] = id
+	return id, nil
+}
+
+func (p *FakePaymentProvider) Refund(ctx context.Context, reference string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.charges[reference]; !ok {
+		return errors.New("charge not found")
+	}
+	delete(p.charges, reference)
+	return nil
+}
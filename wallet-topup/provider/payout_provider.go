@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+type fakePayout struct {
+	submittedAt time.Time
+	bankAccount string
+}
+
+// FakePayoutProvider is a local stand-in for a bank payout gateway. Payouts are
+// reported as paid once SettleAfter has elapsed, except for bank accounts
+// ending in "0000", which always fail.
+type FakePayoutProvider struct {
+	SettleAfter time.Duration
+
+	mu      sync.Mutex
+	payouts map[string]fakePayout
+}
+
+func NewFakePayoutProvider(settleAfter time.Duration) *FakePayoutProvider {
+	return &FakePayoutProvider{
+		SettleAfter: settleAfter,
+		payouts:     make(map[string]fakePayout),
+	}
+}
+
+func (p *FakePayoutProvider) SubmitPayout(ctx context.Context, withdrawal *model.Withdrawal) (string, error) {
+	if withdrawal.BankAccount == "" {
+		return "", errors.New("bank account is required")
+	}
+
+	ref := "payout_" + uuid.New().String()
+	p.mu.Lock()
+	p.payouts[ref] = fakePayout{submittedAt: time.Now(), bankAccount: withdrawal.BankAccount}
+	p.mu.Unlock()
+	return ref, nil
+}
+
+func (p *FakePayoutProvider) GetPayoutStatus(ctx context.Context, reference string) (string, string, error) {
+	p.mu.Lock()
+	payout, ok := p.payouts[reference]
+	p.mu.Unlock()
+	if !ok {
+		return "", "", errors.New("payout not found")
+	}
+
+	if time.Since(payout.submittedAt) < p.SettleAfter {
+		return model.PayoutPending, "", nil
+	}
+	if strings.HasSuffix(payout.bankAccount, "0000") {
+		return model.PayoutFailed, "bank account closed", nil
+	}
+	return model.PayoutPaid, "", nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type AdjustmentRepo struct {
+	DB *gorm.DB
+}
+
+func NewAdjustmentRepo(db *gorm.DB) *AdjustmentRepo {
+	return &AdjustmentRepo{DB: db}
+}
+
+func (r *AdjustmentRepo) CreateAdjustment(adjustment *model.Adjustment) error {
+	return r.DB.Create(adjustment).Error
+}
+
+func (r *AdjustmentRepo) GetAdjustmentByID(adjustmentID string) (*model.Adjustment, error) {
+	var adjustment model.Adjustment
+	if err := r.DB.First(&adjustment, "adjustment_id = ?", adjustmentID).Error; err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+func (r *AdjustmentRepo) ListAdjustments(status string, limit int) ([]model.Adjustment, error) {
+	var adjustments []model.Adjustment
+	q := r.DB.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Find(&adjustments).Error
+	return adjustments, err
+}
+
+func (r *AdjustmentRepo) UpdateAdjustmentReview(adjustment *model.Adjustment) error {
+	res := r.DB.Model(&model.Adjustment{}).
+		Where("adjustment_id = ? AND status = ?", adjustment.AdjustmentID, model.AdjustmentPending).
+		Updates(map[string]interface{}{
+			"status":      adjustment.Status,
+			"reviewed_by": adjustment.ReviewedBy,
+			"review_note": adjustment.ReviewNote,
+			"reviewed_at": adjustment.ReviewedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
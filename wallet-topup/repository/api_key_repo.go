@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepo struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) *APIKeyRepo {
+	return &APIKeyRepo{DB: db}
+}
+
+func (r *APIKeyRepo) CreateAPIKey(key *model.APIKey) error {
+	return r.DB.Create(key).Error
+}
+
+func (r *APIKeyRepo) GetAPIKey(keyID string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.DB.First(&key, "key_id = ?", keyID).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepo) ListAPIKeys(merchantID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.DB.Where("merchant_id = ?", merchantID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *APIKeyRepo) ExpireAPIKey(keyID string, at time.Time) error {
+	return r.updateActive(keyID, map[string]interface{}{"expires_at": at})
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(keyID string, at time.Time) error {
+	return r.updateActive(keyID, map[string]interface{}{"status": model.APIKeyRevoked, "revoked_at": at})
+}
+
+func (r *APIKeyRepo) updateActive(keyID string, updates map[string]interface{}) error {
+	res := r.DB.Model(&model.APIKey{}).
+		Where("key_id = ? AND status = ?", keyID, model.APIKeyActive).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
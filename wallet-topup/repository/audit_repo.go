@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepo struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) *AuditRepo {
+	return &AuditRepo{DB: db}
+}
+
+func (r *AuditRepo) CreateAuditLog(entry *model.AuditLog) error {
+	return r.DB.Create(entry).Error
+}
+
+func (r *AuditRepo) ListAuditLogs(entityType, entityID string) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	err := r.DB.
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at").
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type AutoTopUpRepo struct {
+	DB *gorm.DB
+}
+
+func NewAutoTopUpRepo(db *gorm.DB) *AutoTopUpRepo {
+	return &AutoTopUpRepo{DB: db}
+}
+
+func (r *AutoTopUpRepo) GetConfig(userID uint) (*model.AutoTopUpConfig, error) {
+	var cfg model.AutoTopUpConfig
+	if err := r.DB.First(&cfg, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (r *AutoTopUpRepo) SaveConfig(cfg *model.AutoTopUpConfig) error {
+	return r.DB.Save(cfg).Error
+}
+
+func (r *AutoTopUpRepo) CreateAutoTopUp(topUp *model.AutoTopUp) error {
+	return r.DB.Create(topUp).Error
+}
+
+func (r *AutoTopUpRepo) SumSucceededSince(userID uint, since time.Time) (float64, error) {
+	var total float64
+	err := r.DB.Model(&model.AutoTopUp{}).
+		Where("user_id = ? AND status = ? AND created_at >= ?", userID, model.RunSucceeded, since).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
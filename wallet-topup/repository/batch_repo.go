@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type BatchRepo struct {
+	DB *gorm.DB
+}
+
+func NewBatchRepo(db *gorm.DB) *BatchRepo {
+	return &BatchRepo{DB: db}
+}
+
+func (r *BatchRepo) CreateBatch(batch *model.Batch, rows []model.BatchRow) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+func (r *BatchRepo) GetBatchByID(batchID string) (*model.Batch, error) {
+	var batch model.Batch
+	if err := r.DB.First(&batch, "batch_id = ?", batchID).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *BatchRepo) GetBatchByReference(reference string) (*model.Batch, error) {
+	var batch model.Batch
+	if err := r.DB.First(&batch, "reference = ?", reference).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *BatchRepo) ListRows(batchID string) ([]model.BatchRow, error) {
+	var rows []model.BatchRow
+	err := r.DB.Where("batch_id = ?", batchID).Order("row_number").Find(&rows).Error
+	return rows, err
+}
+
+func (r *BatchRepo) ListPendingRows(batchID string, limit int) ([]model.BatchRow, error) {
+	var rows []model.BatchRow
+	err := r.DB.
+		Where("batch_id = ? AND status = ?", batchID, model.BatchRowPending).
+		Order("row_number").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *BatchRepo) UpdateRowStatus(batchID string, rowNumber int, fromStatus, status, errMsg string) error {
+	res := r.DB.Model(&model.BatchRow{}).
+		Where("batch_id = ? AND row_number = ? AND status = ?", batchID, rowNumber, fromStatus).
+		Updates(map[string]interface{}{"status": status, "error": errMsg, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *BatchRepo) RefreshBatchProgress(batchID string) (*model.Batch, error) {
+	var counts struct {
+		Pending   int
+		Succeeded int
+		Failed    int
+	}
+	err := r.DB.Model(&model.BatchRow{}).
+		Where("batch_id = ?", batchID).
+		Select(
+			"COUNT(*) FILTER (WHERE status = ?) AS pending, COUNT(*) FILTER (WHERE status = ?) AS succeeded, COUNT(*) FILTER (WHERE status = ?) AS failed",
+			model.BatchRowPending, model.BatchRowCredited, model.BatchRowFailed,
+		).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"succeeded_rows": counts.Succeeded,
+		"failed_rows":    counts.Failed,
+		"status":         model.BatchProcessing,
+		"updated_at":     now,
+	}
+	if counts.Pending == 0 {
+		updates["status"] = model.BatchCompleted
+		if counts.Failed > 0 {
+			updates["status"] = model.BatchCompletedWithErrors
+		}
+		updates["completed_at"] = now
+	}
+	if err := r.DB.Model(&model.Batch{}).Where("batch_id = ?", batchID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return r.GetBatchByID(batchID)
+}
+
+func (r *BatchRepo) ListUnfinishedBatches(limit int) ([]model.Batch, error) {
+	var batches []model.Batch
+	err := r.DB.
+		Where("status IN ?", []string{model.BatchPending, model.BatchProcessing}).
+		Order("created_at").
+		Limit(limit).
+		Find(&batches).Error
+	return batches, err
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CredentialRepo struct {
+	DB *gorm.DB
+}
+
+func NewCredentialRepo(db *gorm.DB) *CredentialRepo {
+	return &CredentialRepo{DB: db}
+}
+
+func (r *CredentialRepo) GetCredential(username string) (*model.Credential, error) {
+	var c model.Credential
+	if err := r.DB.First(&c, "username = ?", username).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CredentialRepo) CreateCredential(c *model.Credential) (bool, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(c)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type HoldRepo struct {
+	DB *gorm.DB
+}
+
+func NewHoldRepo(db *gorm.DB) *HoldRepo {
+	return &HoldRepo{DB: db}
+}
+
+func (r *HoldRepo) CreateHold(hold *model.Hold) error {
+	return r.DB.Create(hold).Error
+}
+
+func (r *HoldRepo) GetHoldByID(holdID string) (*model.Hold, error) {
+	var hold model.Hold
+	if err := r.DB.First(&hold, "hold_id = ?", holdID).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *HoldRepo) UpdateHoldStatus(holdID, fromStatus, toStatus string) error {
+	res := r.DB.Model(&model.Hold{}).
+		Where("hold_id = ? AND status = ?", holdID, fromStatus).
+		Updates(map[string]interface{}{"status": toStatus, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *HoldRepo) SumActiveHolds(userID uint) (float64, error) {
+	var total float64
+	err := r.activeHolds(userID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *HoldRepo) ListActiveHolds(userID uint) ([]model.Hold, error) {
+	var holds []model.Hold
+	err := r.activeHolds(userID).Order("created_at").Find(&holds).Error
+	return holds, err
+}
+
+func (r *HoldRepo) ListExpiredHolds(now time.Time, limit int) ([]model.Hold, error) {
+	var holds []model.Hold
+	err := r.DB.
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", model.HoldActive, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&holds).Error
+	return holds, err
+}
+
+func (r *HoldRepo) activeHolds(userID uint) *gorm.DB {
+	return r.DB.Model(&model.Hold{}).
+		Where("user_id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)", userID, model.HoldActive, time.Now())
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type LedgerRepo struct {
+	DB *gorm.DB
+}
+
+func NewLedgerRepo(db *gorm.DB) *LedgerRepo {
+	return &LedgerRepo{DB: db}
+}
+
+func (r *LedgerRepo) CreateEntries(entries ...*model.LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.DB.Create(entries).Error
+}
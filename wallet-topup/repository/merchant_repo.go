@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type MerchantRepo struct {
+	DB *gorm.DB
+}
+
+func NewMerchantRepo(db *gorm.DB) *MerchantRepo {
+	return &MerchantRepo{DB: db}
+}
+
+func (r *MerchantRepo) GetMerchantByID(merchantID uint) (*model.Merchant, error) {
+	var merchant model.Merchant
+	if err := r.DB.First(&merchant, merchantID).Error; err != nil {
+		return nil, err
+	}
+	return &merchant, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OAuthClientRepo struct {
+	DB *gorm.DB
+}
+
+func NewOAuthClientRepo(db *gorm.DB) *OAuthClientRepo {
+	return &OAuthClientRepo{DB: db}
+}
+
+func (r *OAuthClientRepo) GetClient(clientID string) (*model.OAuthClient, error) {
+	var c model.OAuthClient
+	if err := r.DB.First(&c, "client_id = ?", clientID).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *OAuthClientRepo) CreateClient(c *model.OAuthClient) (bool, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(c)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
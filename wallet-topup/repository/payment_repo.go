@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepo struct {
+	DB *gorm.DB
+}
+
+func NewPaymentRepo(db *gorm.DB) *PaymentRepo {
+	return &PaymentRepo{DB: db}
+}
+
+func (r *PaymentRepo) CreatePayment(payment *model.Payment) error {
+	return r.DB.Create(payment).Error
+}
+
+func (r *PaymentRepo) GetPaymentByID(paymentID string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.DB.First(&payment, "payment_id = ?", paymentID).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepo) GetPaymentByReference(merchantID uint, orderReference string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.DB.First(&payment, "merchant_id = ? AND order_reference = ?", merchantID, orderReference).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepo) GetPaymentByIDForUpdate(paymentID string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, "payment_id = ?", paymentID).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PaymentRepo) UpdatePaymentRefund(paymentID string, refundedAmount float64, status string) error {
+	return r.DB.Model(&model.Payment{}).
+		Where("payment_id = ?", paymentID).
+		Updates(map[string]interface{}{
+			"refunded_amount": refundedAmount,
+			"status":          status,
+			"updated_at":      time.Now(),
+		}).Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type ScheduleRepo struct {
+	DB *gorm.DB
+}
+
+func NewScheduleRepo(db *gorm.DB) *ScheduleRepo {
+	return &ScheduleRepo{DB: db}
+}
+
+func (r *ScheduleRepo) CreateSchedule(schedule *model.TopUpSchedule) error {
+	return r.DB.Create(schedule).Error
+}
+
+func (r *ScheduleRepo) GetScheduleByID(scheduleID string) (*model.TopUpSchedule, error) {
+	var schedule model.TopUpSchedule
+	if err := r.DB.First(&schedule, "schedule_id = ?", scheduleID).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *ScheduleRepo) UpdateScheduleProgress(schedule *model.TopUpSchedule) error {
+	return r.DB.Model(&model.TopUpSchedule{}).
+		Where("schedule_id = ?", schedule.ScheduleID).
+		Updates(map[string]interface{}{
+			"next_run_at":            schedule.NextRunAt,
+			"retry_count":            schedule.RetryCount,
+			"last_run_at":            schedule.LastRunAt,
+			"pending_transaction_id": schedule.PendingTransactionID,
+			"updated_at":             time.Now(),
+		}).Error
+}
+
+func (r *ScheduleRepo) UpdateScheduleStatus(scheduleID, fromStatus, toStatus string, nextRunAt time.Time) error {
+	res := r.DB.Model(&model.TopUpSchedule{}).
+		Where("schedule_id = ? AND status = ?", scheduleID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":      toStatus,
+			"next_run_at": nextRunAt,
+			"retry_count": 0,
+			"updated_at":  time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *ScheduleRepo) ListDueSchedules(now time.Time, limit int) ([]model.TopUpSchedule, error) {
+	var schedules []model.TopUpSchedule
+	err := r.DB.
+		Where("status = ? AND next_run_at <= ?", model.ScheduleActive, now).
+		Order("next_run_at").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *ScheduleRepo) ClaimSchedule(scheduleID string, expected, leaseUntil time.Time) error {
+	res := r.DB.Model(&model.TopUpSchedule{}).
+		Where("schedule_id = ? AND status = ? AND next_run_at = ?", scheduleID, model.ScheduleActive, expected).
+		Update("next_run_at", leaseUntil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *ScheduleRepo) CreateRun(run *model.ScheduleRun) error {
+	return r.DB.Create(run).Error
+}
+
+func (r *ScheduleRepo) ListRuns(scheduleID string, limit int) ([]model.ScheduleRun, error) {
+	var runs []model.ScheduleRun
+	err := r.DB.
+		Where("schedule_id = ?", scheduleID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"time"
 	"wallet-topup/model"
 
 	"gorm.io/gorm"
@@ -26,6 +27,41 @@ func (r *TransactionRepo) GetTransactionByID(transactionID string) (*model.Trans
 	return &txn, nil
 }
 
-func (r *TransactionRepo) UpdateTransactionStatus(transactionID string, status string) error {
-	return r.DB.Model(&model.Transaction{}).Where("transaction_id = ?", transactionID).Update("status", status).Error
+func (r *TransactionRepo) CompleteTransaction(transactionID string) error {
+	res := r.DB.Model(&model.Transaction{}).
+		Where("transaction_id = ? AND status = ? AND expires_at > ?", transactionID, "verified", time.Now()).
+		Update("status", "completed")
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *TransactionRepo) SumTopUpsSince(userID uint, since time.Time) (float64, error) {
+	var total float64
+	err := r.DB.Model(&model.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Where("status = ? OR (status = ? AND expires_at > ?)", "completed", "verified", time.Now()).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *TransactionRepo) ExtendTransaction(transactionID string, expiresAt time.Time) error {
+	res := r.DB.Model(&model.Transaction{}).
+		Where("transaction_id = ? AND status = ? AND expires_at > ?", transactionID, "verified", time.Now()).
+		Updates(map[string]interface{}{
+			"expires_at": expiresAt,
+			"extensions": gorm.Expr("extensions + 1"),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
 }
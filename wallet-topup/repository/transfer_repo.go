@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type TransferRepo struct {
+	DB *gorm.DB
+}
+
+func NewTransferRepo(db *gorm.DB) *TransferRepo {
+	return &TransferRepo{DB: db}
+}
+
+func (r *TransferRepo) CreateTransfer(transfer *model.Transfer) error {
+	return r.DB.Create(transfer).Error
+}
+
+func (r *TransferRepo) GetTransferByID(transferID string) (*model.Transfer, error) {
+	var transfer model.Transfer
+	if err := r.DB.First(&transfer, "transfer_id = ?", transferID).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *TransferRepo) SumOutgoingSince(userID uint, since time.Time) (float64, error) {
+	var total float64
+	err := r.DB.Model(&model.Transfer{}).
+		Where("from_user_id = ? AND created_at >= ?", userID, since).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
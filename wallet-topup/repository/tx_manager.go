@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type TxManager struct {
+	DB *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{DB: db}
+}
+
+// WithinTransaction runs fn with repositories bound to a single database
+// transaction. The transaction is rolled back if fn returns an error.
+func (m *TxManager) WithinTransaction(fn func(repos model.TxRepositories) error) error {
+	return m.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(model.TxRepositories{
+			Users:         NewUserRepo(tx),
+			Transactions:  NewTransactionRepo(tx),
+			Transfers:     NewTransferRepo(tx),
+			Ledger:        NewLedgerRepo(tx),
+			Withdrawals:   NewWithdrawalRepo(tx),
+			Holds:         NewHoldRepo(tx),
+			Payments:      NewPaymentRepo(tx),
+			Batches:       NewBatchRepo(tx),
+			Adjustments:   NewAdjustmentRepo(tx),
+			Audit:         NewAuditRepo(tx),
+			StatusChanges: NewWalletStatusRepo(tx),
+		})
+	})
+}
@@ -4,6 +4,12 @@ import (
 	"wallet-topup/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var heldAmountSQL = gorm.Expr(
+	"(SELECT COALESCE(SUM(h.amount), 0) FROM holds h WHERE h.user_id = users.user_id AND h.status = ? AND (h.expires_at IS NULL OR h.expires_at > now()))",
+	model.HoldActive,
 )
 
 type UserRepo struct {
@@ -22,6 +28,74 @@ func (r *UserRepo) GetUserByID(userID uint) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *UserRepo) GetUserByIDForUpdate(userID uint) (*model.User, error) {
+	var user model.User
+	if err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepo) UpdateUserBalance(userID uint, amount float64) error {
 	return r.DB.Model(&model.User{}).Where("user_id = ?", userID).Update("balance", gorm.Expr("balance + ?", amount)).Error
 }
+
+// DebitUserBalance subtracts amount from the balance only when enough of it is
+// available, i.e. not reserved by an active hold.
+func (r *UserRepo) DebitUserBalance(userID uint, amount float64) error {
+	res := r.DB.Model(&model.User{}).
+		Where("user_id = ? AND balance - ? >= ?", userID, heldAmountSQL, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrInsufficientFunds
+	}
+	return nil
+}
+
+func (r *UserRepo) UpdateUserStatus(userID uint, fromStatus, status string) error {
+	res := r.DB.Model(&model.User{}).
+		Where("user_id = ? AND status = ?", userID, fromStatus).
+		Update("status", status)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *UserRepo) UpdateUserTier(userID uint, tier string) error {
+	return r.DB.Model(&model.User{}).Where("user_id = ?", userID).Update("kyc_tier", tier).Error
+}
+
+func (r *UserRepo) CreateUser(user *model.User) (bool, error) {
+	res := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "external_ref"}},
+		DoNothing: true,
+	}).Create(user)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *UserRepo) GetUserByExternalRef(externalRef string) (*model.User, error) {
+	var user model.User
+	if err := r.DB.First(&user, "external_ref = ?", externalRef).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepo) UpdateUserProfile(user *model.User) error {
+	return r.DB.Model(&model.User{}).Where("user_id = ?", user.UserID).Updates(map[string]interface{}{
+		"full_name":  user.FullName,
+		"email":      user.Email,
+		"phone":      user.Phone,
+		"updated_at": user.UpdatedAt,
+	}).Error
+}
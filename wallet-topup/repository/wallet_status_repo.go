@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type WalletStatusRepo struct {
+	DB *gorm.DB
+}
+
+func NewWalletStatusRepo(db *gorm.DB) *WalletStatusRepo {
+	return &WalletStatusRepo{DB: db}
+}
+
+func (r *WalletStatusRepo) CreateStatusChange(change *model.WalletStatusChange) error {
+	return r.DB.Create(change).Error
+}
+
+func (r *WalletStatusRepo) ListStatusChanges(userID uint) ([]model.WalletStatusChange, error) {
+	var changes []model.WalletStatusChange
+	err := r.DB.Where("user_id = ?", userID).Order("created_at").Find(&changes).Error
+	return changes, err
+}
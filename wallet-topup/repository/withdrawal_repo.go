@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+type WithdrawalRepo struct {
+	DB *gorm.DB
+}
+
+func NewWithdrawalRepo(db *gorm.DB) *WithdrawalRepo {
+	return &WithdrawalRepo{DB: db}
+}
+
+func (r *WithdrawalRepo) CreateWithdrawal(withdrawal *model.Withdrawal) error {
+	return r.DB.Create(withdrawal).Error
+}
+
+func (r *WithdrawalRepo) GetWithdrawalByID(withdrawalID string) (*model.Withdrawal, error) {
+	var withdrawal model.Withdrawal
+	if err := r.DB.First(&withdrawal, "withdrawal_id = ?", withdrawalID).Error; err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+func (r *WithdrawalRepo) UpdateWithdrawal(withdrawal *model.Withdrawal, fromStatus string) error {
+	res := r.DB.Model(&model.Withdrawal{}).
+		Where("withdrawal_id = ? AND status = ?", withdrawal.WithdrawalID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":           withdrawal.Status,
+			"hold_id":          withdrawal.HoldID,
+			"payout_reference": withdrawal.PayoutReference,
+			"failure_reason":   withdrawal.FailureReason,
+			"updated_at":       withdrawal.UpdatedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return model.ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *WithdrawalRepo) SumActiveSince(userID uint, since time.Time) (float64, error) {
+	var total float64
+	err := r.DB.Model(&model.Withdrawal{}).
+		Where("user_id = ? AND created_at >= ? AND status <> ?", userID, since, model.WithdrawalFailed).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
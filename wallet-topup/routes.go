@@ -0,0 +1,115 @@
+package main
+
+import (
+	"time"
+	"wallet-topup/handler"
+	"wallet-topup/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiHandlers holds every handler served under /api.
+type apiHandlers struct {
+	auth         *handler.AuthHandler
+	wallet       *handler.WalletHandler
+	user         *handler.UserHandler
+	transfer     *handler.TransferHandler
+	withdrawal   *handler.WithdrawalHandler
+	hold         *handler.HoldHandler
+	payment      *handler.PaymentHandler
+	schedule     *handler.ScheduleHandler
+	autoTopUp    *handler.AutoTopUpHandler
+	batch        *handler.BatchHandler
+	adjustment   *handler.AdjustmentHandler
+	walletStatus *handler.WalletStatusHandler
+	kyc          *handler.KYCHandler
+	oauth        *handler.OAuthHandler
+	apiKey       *handler.APIKeyHandler
+}
+
+// apiVersion is one version of the API mounted at prefix. A new version is
+// added as another entry with its own register function, so it can use
+// different handlers or DTOs while older versions keep being served.
+type apiVersion struct {
+	prefix   string
+	register func(g *gin.RouterGroup, h *apiHandlers, scope func(...string) gin.HandlerFunc)
+	// deprecatedAt, when set, marks the version deprecated as of that date.
+	deprecatedAt time.Time
+	// sunset, when set on a deprecated version, says when it will be
+	// removed.
+	sunset time.Time
+	// successor is the prefix clients should move to once deprecated.
+	successor string
+}
+
+// aliasDeprecatedAt is when the unversioned /api paths were deprecated, the
+// day versioned prefixes shipped.
+var aliasDeprecatedAt = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+// mountAPI serves each version under its prefix. The first version is also
+// served at the unversioned /api, which predates versioning. That alias is
+// always marked deprecated in favour of the versioned prefix.
+func mountAPI(r *gin.Engine, versions []apiVersion, aliasSunset time.Time, auth gin.HandlerFunc, h *apiHandlers, scope func(...string) gin.HandlerFunc) {
+	for _, v := range versions {
+		var handlers []gin.HandlerFunc
+		if !v.deprecatedAt.IsZero() {
+			handlers = append(handlers, middleware.Deprecation(v.deprecatedAt, v.sunset, v.successor))
+		}
+		v.register(r.Group(v.prefix, append(handlers, auth)...), h, scope)
+	}
+
+	alias := versions[0]
+	alias.register(r.Group("/api", middleware.Deprecation(aliasDeprecatedAt, aliasSunset, alias.prefix), auth), h, scope)
+}
+
+func registerV1(api *gin.RouterGroup, h *apiHandlers, scope func(...string) gin.HandlerFunc) {
+	api.POST("/logout", h.auth.Logout)
+	api.POST("/logout-all", h.auth.LogoutAll)
+	api.POST("/quote", scope("topup:write"), h.wallet.Quote)
+	api.POST("/verify", scope("topup:write"), h.wallet.Verify)
+	api.POST("/confirm", scope("topup:write"), h.wallet.Confirm)
+	api.POST("/transactions/:id/extend", scope("topup:write"), h.wallet.Extend)
+	api.POST("/users", scope("user:create"), h.user.Create)
+	api.GET("/users", scope("user:lookup"), h.user.Lookup)
+	api.GET("/users/:id", scope("user:read"), h.user.Get)
+	api.PATCH("/users/:id", scope("user:write"), h.user.Update)
+	api.POST("/transfers", scope("transfer:create"), h.transfer.Create)
+	api.POST("/withdrawals", scope("withdrawal:create"), h.withdrawal.Request)
+	api.GET("/withdrawals/:id", scope("txn:read"), h.withdrawal.Get)
+	api.POST("/withdrawals/:id/refresh", scope("withdrawal:create"), h.withdrawal.Refresh)
+	api.POST("/holds", scope("hold:write"), h.hold.Place)
+	api.POST("/holds/:id/capture", scope("hold:write"), h.hold.Capture)
+	api.POST("/holds/:id/release", scope("hold:write"), h.hold.Release)
+	api.GET("/users/:id/balance", scope("txn:read"), h.hold.Balance)
+	api.POST("/payments/authorizations", scope("payment:authorize"), h.payment.Authorize)
+	api.POST("/payments", scope("payment:create"), h.payment.Create)
+	api.GET("/payments/:id", scope("txn:read"), h.payment.Get)
+	api.POST("/payments/:id/refunds", scope("refund:create"), h.payment.Refund)
+	api.POST("/schedules", scope("topup:write"), h.schedule.Create)
+	api.GET("/schedules/:id", scope("txn:read"), h.schedule.Get)
+	api.POST("/schedules/:id/pause", scope("topup:write"), h.schedule.Pause)
+	api.POST("/schedules/:id/resume", scope("topup:write"), h.schedule.Resume)
+	api.GET("/schedules/:id/runs", scope("txn:read"), h.schedule.Runs)
+	api.GET("/users/:id/auto-topup", scope("txn:read"), h.autoTopUp.Get)
+	api.PUT("/users/:id/auto-topup", scope("topup:write"), h.autoTopUp.Save)
+	api.POST("/batches", scope("batch:write"), h.batch.Submit)
+	api.GET("/batches/:id", scope("batch:read"), h.batch.Get)
+	api.GET("/batches/:id/rows", scope("batch:read"), h.batch.Rows)
+
+	admin := api.Group("/admin")
+	admin.POST("/adjustments", scope("admin:adjust"), h.adjustment.Propose)
+	admin.GET("/adjustments", scope("admin:adjust"), h.adjustment.List)
+	admin.GET("/adjustments/:id", scope("admin:adjust"), h.adjustment.Get)
+	admin.POST("/adjustments/:id/approve", scope("admin:adjust"), h.adjustment.Approve)
+	admin.POST("/adjustments/:id/reject", scope("admin:adjust"), h.adjustment.Reject)
+	admin.PUT("/users/:id/status", scope("admin:status"), h.walletStatus.Change)
+	admin.GET("/users/:id/status-history", scope("admin:status"), h.walletStatus.History)
+	admin.GET("/users/:id/kyc", scope("admin:kyc"), h.kyc.Get)
+	admin.POST("/credentials", scope("admin:credentials"), h.auth.CreateCredential)
+	admin.POST("/oauth-clients", scope("admin:credentials"), h.oauth.CreateClient)
+	admin.PUT("/users/:id/kyc", scope("admin:kyc"), h.kyc.ChangeTier)
+	admin.POST("/merchants/:id/api-keys", scope("admin:api-keys"), h.apiKey.Issue)
+	admin.GET("/merchants/:id/api-keys", scope("admin:api-keys"), h.apiKey.List)
+	admin.POST("/api-keys/:key_id/rotate", scope("admin:api-keys"), h.apiKey.Rotate)
+	admin.POST("/api-keys/:key_id/revoke", scope("admin:api-keys"), h.apiKey.Revoke)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupAPI mounts the real v1 routes behind an auth middleware that rejects
+// every call, so the handlers themselves are never reached.
+func setupAPI(aliasSunset time.Time) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	reject := func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+	noScope := func(...string) gin.HandlerFunc { return func(c *gin.Context) { c.Next() } }
+	versions := []apiVersion{{prefix: "/api/v1", register: registerV1}}
+	mountAPI(r, versions, aliasSunset, reject, &apiHandlers{}, noScope)
+	return r
+}
+
+func serve(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestMountAPI_AliasServesV1Routes(t *testing.T) {
+	r := setupAPI(time.Time{})
+
+	v1 := map[string]bool{}
+	alias := map[string]bool{}
+	for _, route := range r.Routes() {
+		if rest, ok := strings.CutPrefix(route.Path, "/api/v1/"); ok {
+			v1[route.Method+" /"+rest] = true
+		} else if rest, ok := strings.CutPrefix(route.Path, "/api/"); ok {
+			alias[route.Method+" /"+rest] = true
+		}
+	}
+
+	assert.NotEmpty(t, v1)
+	assert.Equal(t, v1, alias)
+}
+
+func TestMountAPI_OnlyAliasIsDeprecated(t *testing.T) {
+	sunset := time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC)
+	r := setupAPI(sunset)
+
+	w := serve(r, http.MethodPost, "/api/v1/verify")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Empty(t, w.Header().Get("Link"))
+
+	w = serve(r, http.MethodPost, "/api/verify")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1>; rel="successor-version"`, w.Header().Get("Link"))
+}
+
+func TestMountAPI_AliasWithoutSunset(t *testing.T) {
+	r := setupAPI(time.Time{})
+
+	w := serve(r, http.MethodGet, "/api/payments/p1")
+
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1>; rel="successor-version"`, w.Header().Get("Link"))
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxAdjustmentAmount = 1000000.00
+
+	adjustmentListLimit = 100
+)
+
+type AdjustmentService struct {
+	txManager      model.TxManager
+	adjustmentRepo model.AdjustmentRepository
+	auditRepo      model.AuditRepository
+	logger         logs.Logger
+}
+
+func NewAdjustmentService(
+	txManager model.TxManager,
+	adjustmentRepo model.AdjustmentRepository,
+	auditRepo model.AuditRepository,
+	logger logs.Logger,
+) model.AdjustmentService {
+	return &AdjustmentService{
+		txManager:      txManager,
+		adjustmentRepo: adjustmentRepo,
+		auditRepo:      auditRepo,
+		logger:         logger,
+	}
+}
+
+func (s *AdjustmentService) ProposeAdjustment(ctx context.Context, actor string, adjustment *model.Adjustment) (*model.Adjustment, error) {
+	if actor == "" {
+		return nil, model.Invalid("actor identity is required")
+	}
+	if adjustment.Direction != model.AdjustmentCredit && adjustment.Direction != model.AdjustmentDebit {
+		return nil, model.Invalid("direction must be credit or debit")
+	}
+	if adjustment.Amount <= 0 {
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if adjustment.Amount > MaxAdjustmentAmount {
+		return nil, model.LimitExceeded("amount exceeds maximum allowed")
+	}
+	if !slices.Contains(model.AdjustmentReasonCodes, adjustment.ReasonCode) {
+		return nil, model.Invalid("unknown reason code")
+	}
+	if adjustment.Evidence == "" {
+		return nil, model.Invalid("evidence is required")
+	}
+
+	adjustment.AdjustmentID = uuid.New().String()
+	adjustment.Status = model.AdjustmentPending
+	adjustment.ProposedBy = actor
+	adjustment.ReviewedBy = ""
+	adjustment.ReviewedAt = nil
+	adjustment.CreatedAt = time.Now()
+
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if _, err := repos.Users.GetUserByID(adjustment.UserID); err != nil {
+			return lookupError(err, "user")
+		}
+		if err := repos.Adjustments.CreateAdjustment(adjustment); err != nil {
+			return err
+		}
+		return repos.Audit.CreateAuditLog(newAuditLog(actor, "adjustment.proposed", "adjustment", adjustment.AdjustmentID, map[string]interface{}{
+			"user_id":     adjustment.UserID,
+			"direction":   adjustment.Direction,
+			"amount":      adjustment.Amount,
+			"reason_code": adjustment.ReasonCode,
+			"evidence":    adjustment.Evidence,
+		}))
+	})
+	if err != nil {
+		s.logger.Warnf("propose adjustment by %s failed: %v", actor, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("adjustment proposed: %s by %s", adjustment.AdjustmentID, actor)
+	return adjustment, nil
+}
+
+// ApproveAdjustment posts a pending adjustment to the wallet. The approver
+// must be a different admin from the one who proposed it.
+func (s *AdjustmentService) ApproveAdjustment(ctx context.Context, actor, adjustmentID, note string) (*model.Adjustment, error) {
+	adjustment, err := s.reviewable(actor, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	adjustment.Status = model.AdjustmentApproved
+	adjustment.ReviewedBy = actor
+	adjustment.ReviewNote = note
+	adjustment.ReviewedAt = &now
+
+	err = s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Adjustments.UpdateAdjustmentReview(adjustment); err != nil {
+			return err
+		}
+		// Frozen and suspended wallets can still be corrected; closed ones
+		// must stay at zero.
+		user, err := repos.Users.GetUserByIDForUpdate(adjustment.UserID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		if user.Status == model.WalletClosed {
+			return model.ErrWalletClosed
+		}
+
+		amount := adjustment.Amount
+		if adjustment.Direction == model.AdjustmentDebit {
+			if err := repos.Users.DebitUserBalance(adjustment.UserID, amount); err != nil {
+				return err
+			}
+			amount = -amount
+		} else if err := repos.Users.UpdateUserBalance(adjustment.UserID, amount); err != nil {
+			return err
+		}
+
+		if err := repos.Ledger.CreateEntries(&model.LedgerEntry{
+			EntryID:   uuid.New().String(),
+			UserID:    adjustment.UserID,
+			Amount:    amount,
+			Type:      "adjustment_" + adjustment.Direction,
+			Reference: adjustment.AdjustmentID,
+			CreatedAt: now,
+		}); err != nil {
+			return err
+		}
+		return repos.Audit.CreateAuditLog(newAuditLog(actor, "adjustment.approved", "adjustment", adjustment.AdjustmentID, map[string]interface{}{
+			"note": note,
+		}))
+	})
+	if err != nil {
+		s.logger.Warnf("approve adjustment %s by %s failed: %v", adjustmentID, actor, err)
+		s.audit(newAuditLog(actor, "adjustment.approval_failed", "adjustment", adjustmentID, map[string]interface{}{
+			"error": err.Error(),
+		}))
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("adjustment approved: %s by %s", adjustmentID, actor)
+	return adjustment, nil
+}
+
+func (s *AdjustmentService) RejectAdjustment(ctx context.Context, actor, adjustmentID, note string) (*model.Adjustment, error) {
+	adjustment, err := s.reviewable(actor, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	adjustment.Status = model.AdjustmentRejected
+	adjustment.ReviewedBy = actor
+	adjustment.ReviewNote = note
+	adjustment.ReviewedAt = &now
+
+	err = s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Adjustments.UpdateAdjustmentReview(adjustment); err != nil {
+			return err
+		}
+		return repos.Audit.CreateAuditLog(newAuditLog(actor, "adjustment.rejected", "adjustment", adjustment.AdjustmentID, map[string]interface{}{
+			"note": note,
+		}))
+	})
+	if err != nil {
+		s.logger.Warnf("reject adjustment %s by %s failed: %v", adjustmentID, actor, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("adjustment rejected: %s by %s", adjustmentID, actor)
+	return adjustment, nil
+}
+
+func (s *AdjustmentService) GetAdjustment(ctx context.Context, adjustmentID string) (*model.Adjustment, []model.AuditLog, error) {
+	adjustment, err := s.adjustmentRepo.GetAdjustmentByID(adjustmentID)
+	if err != nil {
+		return nil, nil, lookupError(err, "adjustment")
+	}
+	history, err := s.auditRepo.ListAuditLogs("adjustment", adjustmentID)
+	if err != nil {
+		s.logger.Error("list audit logs error:", err)
+		return nil, nil, storeError(err)
+	}
+	return adjustment, history, nil
+}
+
+func (s *AdjustmentService) ListAdjustments(ctx context.Context, status string) ([]model.Adjustment, error) {
+	return s.adjustmentRepo.ListAdjustments(status, adjustmentListLimit)
+}
+
+func (s *AdjustmentService) reviewable(actor, adjustmentID string) (*model.Adjustment, error) {
+	if actor == "" {
+		return nil, model.Invalid("actor identity is required")
+	}
+	adjustment, err := s.adjustmentRepo.GetAdjustmentByID(adjustmentID)
+	if err != nil {
+		return nil, lookupError(err, "adjustment")
+	}
+	if adjustment.Status != model.AdjustmentPending {
+		return nil, model.Conflict("adjustment is not pending")
+	}
+	if adjustment.ProposedBy == actor {
+		s.logger.Warnf("%s tried to review own adjustment %s", actor, adjustmentID)
+		s.audit(newAuditLog(actor, "adjustment.self_review_denied", "adjustment", adjustmentID, nil))
+		return nil, model.Invalid("adjustment must be reviewed by a different admin")
+	}
+	return adjustment, nil
+}
+
+func (s *AdjustmentService) audit(entry *model.AuditLog) {
+	if err := s.auditRepo.CreateAuditLog(entry); err != nil {
+		s.logger.Error("write audit log error:", err)
+	}
+}
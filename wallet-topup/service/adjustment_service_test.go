@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupAdjustmentService() (*mocks.AdjustmentRepoMock, *mocks.AuditRepoMock, *mocks.UserRepoMock, *mocks.LedgerRepoMock, model.AdjustmentService) {
+	adjustmentRepo := new(mocks.AdjustmentRepoMock)
+	auditRepo := new(mocks.AuditRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	ledgerRepo := new(mocks.LedgerRepoMock)
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:       userRepo,
+		Ledger:      ledgerRepo,
+		Adjustments: adjustmentRepo,
+		Audit:       auditRepo,
+	}}
+	return adjustmentRepo, auditRepo, userRepo, ledgerRepo, service.NewAdjustmentService(txManager, adjustmentRepo, auditRepo, setupLogger())
+}
+
+func pendingAdjustment(direction string) *model.Adjustment {
+	return &model.Adjustment{
+		AdjustmentID: "adj-1",
+		UserID:       1,
+		Direction:    direction,
+		Amount:       250,
+		ReasonCode:   "error_correction",
+		Evidence:     "ticket-42",
+		Status:       model.AdjustmentPending,
+		ProposedBy:   "alice",
+	}
+}
+
+func TestProposeAdjustment_Success(t *testing.T) {
+	adjustmentRepo, auditRepo, userRepo, _, s := setupAdjustmentService()
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{}, nil)
+	adjustmentRepo.On("CreateAdjustment", mock.Anything).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(e *model.AuditLog) bool {
+		return e.Actor == "alice" && e.Action == "adjustment.proposed"
+	})).Return(nil)
+
+	adjustment, err := s.ProposeAdjustment(context.Background(), "alice", &model.Adjustment{
+		UserID: 1, Direction: model.AdjustmentCredit, Amount: 250, ReasonCode: "goodwill", Evidence: "ticket-42",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.AdjustmentPending, adjustment.Status)
+	assert.Equal(t, "alice", adjustment.ProposedBy)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestProposeAdjustment_UnknownReasonCode(t *testing.T) {
+	adjustmentRepo, _, _, _, s := setupAdjustmentService()
+
+	_, err := s.ProposeAdjustment(context.Background(), "alice", &model.Adjustment{
+		UserID: 1, Direction: model.AdjustmentCredit, Amount: 250, ReasonCode: "because", Evidence: "ticket-42",
+	})
+
+	assert.EqualError(t, err, "unknown reason code")
+	adjustmentRepo.AssertNotCalled(t, "CreateAdjustment", mock.Anything)
+}
+
+func TestApproveAdjustment_SameAdminDenied(t *testing.T) {
+	adjustmentRepo, auditRepo, userRepo, _, s := setupAdjustmentService()
+	adjustmentRepo.On("GetAdjustmentByID", "adj-1").Return(pendingAdjustment(model.AdjustmentCredit), nil)
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil)
+
+	_, err := s.ApproveAdjustment(context.Background(), "alice", "adj-1", "")
+
+	assert.EqualError(t, err, "adjustment must be reviewed by a different admin")
+	adjustmentRepo.AssertNotCalled(t, "UpdateAdjustmentReview", mock.Anything)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestApproveAdjustment_CreditPostsBalance(t *testing.T) {
+	adjustmentRepo, auditRepo, userRepo, ledgerRepo, s := setupAdjustmentService()
+	adjustmentRepo.On("GetAdjustmentByID", "adj-1").Return(pendingAdjustment(model.AdjustmentCredit), nil)
+	adjustmentRepo.On("UpdateAdjustmentReview", mock.Anything).Return(nil)
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1}, nil)
+	userRepo.On("UpdateUserBalance", uint(1), 250.0).Return(nil)
+	ledgerRepo.On("CreateEntries", mock.MatchedBy(func(entries []*model.LedgerEntry) bool {
+		e := entries[0]
+		return e.Amount == 250 && e.Type == "adjustment_credit" && e.Reference == "adj-1"
+	})).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.Anything).Return(nil)
+
+	adjustment, err := s.ApproveAdjustment(context.Background(), "bob", "adj-1", "checked")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.AdjustmentApproved, adjustment.Status)
+	assert.Equal(t, "bob", adjustment.ReviewedBy)
+	userRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+}
+
+func TestApproveAdjustment_DebitInsufficientFunds(t *testing.T) {
+	adjustmentRepo, auditRepo, userRepo, ledgerRepo, s := setupAdjustmentService()
+	adjustmentRepo.On("GetAdjustmentByID", "adj-1").Return(pendingAdjustment(model.AdjustmentDebit), nil)
+	adjustmentRepo.On("UpdateAdjustmentReview", mock.Anything).Return(nil)
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1}, nil)
+	userRepo.On("DebitUserBalance", uint(1), 250.0).Return(model.ErrInsufficientFunds)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(e *model.AuditLog) bool {
+		return e.Action == "adjustment.approval_failed"
+	})).Return(nil)
+
+	_, err := s.ApproveAdjustment(context.Background(), "bob", "adj-1", "")
+
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	ledgerRepo.AssertNotCalled(t, "CreateEntries", mock.Anything)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestRejectAdjustment_Success(t *testing.T) {
+	adjustmentRepo, auditRepo, userRepo, _, s := setupAdjustmentService()
+	adjustmentRepo.On("GetAdjustmentByID", "adj-1").Return(pendingAdjustment(model.AdjustmentCredit), nil)
+	adjustmentRepo.On("UpdateAdjustmentReview", mock.Anything).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(e *model.AuditLog) bool {
+		return e.Actor == "bob" && e.Action == "adjustment.rejected"
+	})).Return(nil)
+
+	adjustment, err := s.RejectAdjustment(context.Background(), "bob", "adj-1", "no evidence")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.AdjustmentRejected, adjustment.Status)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+	auditRepo.AssertExpectations(t)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+)
+
+const (
+	// SignatureMaxSkew is how far a signed request's timestamp may be from
+	// the server clock. Nonces are remembered for twice as long, which covers
+	// every timestamp that would still be accepted.
+	SignatureMaxSkew = 5 * time.Minute
+	// APIKeyRotationGrace is how long a rotated key keeps working.
+	APIKeyRotationGrace = 24 * time.Hour
+)
+
+type APIKeyService struct {
+	repo         model.APIKeyRepository
+	merchantRepo model.MerchantRepository
+	auditRepo    model.AuditRepository
+	redis        RedisClient
+	secrets      *SecretBox
+	logger       logs.Logger
+}
+
+// NewAPIKeyService stores key secrets encrypted with secrets, whose key must
+// stay out of the database.
+func NewAPIKeyService(
+	repo model.APIKeyRepository,
+	merchantRepo model.MerchantRepository,
+	auditRepo model.AuditRepository,
+	redis RedisClient,
+	secrets *SecretBox,
+	logger logs.Logger,
+) model.APIKeyService {
+	return &APIKeyService{
+		repo:         repo,
+		merchantRepo: merchantRepo,
+		auditRepo:    auditRepo,
+		redis:        redis,
+		secrets:      secrets,
+		logger:       logger,
+	}
+}
+
+// CanonicalRequest is the string a request signature covers, one field per
+// line.
+func CanonicalRequest(method, path, timestamp, nonce, bodyHash string) string {
+	return strings.Join([]string{method, path, timestamp, nonce, bodyHash}, "\n")
+}
+
+// SignRequest returns the hex HMAC-SHA256 of the canonical request, keyed
+// with the API key secret.
+func SignRequest(secret, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomToken(prefix string, size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *APIKeyService) IssueKey(ctx context.Context, actor string, merchantID uint) (*model.IssuedAPIKey, error) {
+	merchant, err := s.merchantRepo.GetMerchantByID(merchantID)
+	if err != nil {
+		return nil, lookupError(err, "merchant")
+	}
+	if merchant.Status != "active" {
+		return nil, model.Conflict("merchant is not active")
+	}
+
+	issued, err := s.newKey(actor, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(actor, "api_key.issued", issued.Key, nil)
+	s.logger.Infof("api key issued: %s merchant_id=%d by %s", issued.Key.KeyID, merchantID, actor)
+	return issued, nil
+}
+
+// RotateKey issues a replacement for keyID. The old key keeps working for
+// APIKeyRotationGrace so the merchant can deploy the new one.
+func (s *APIKeyService) RotateKey(ctx context.Context, actor, keyID string) (*model.IssuedAPIKey, error) {
+	old, err := s.repo.GetAPIKey(keyID)
+	if err != nil {
+		return nil, lookupError(err, "api key")
+	}
+	if !old.Usable(time.Now()) {
+		return nil, model.Conflict("api key is revoked or expired")
+	}
+
+	issued, err := s.newKey(actor, old.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(APIKeyRotationGrace)
+	if old.ExpiresAt == nil || expiresAt.Before(*old.ExpiresAt) {
+		if err := s.repo.ExpireAPIKey(keyID, expiresAt); err != nil {
+			s.logger.Error("expire api key error:", err)
+			return nil, storeError(err)
+		}
+	}
+
+	s.audit(actor, "api_key.rotated", old, map[string]interface{}{
+		"replaced_by": issued.Key.KeyID,
+		"expires_at":  expiresAt,
+	})
+	s.logger.Infof("api key rotated: %s -> %s by %s", keyID, issued.Key.KeyID, actor)
+	return issued, nil
+}
+
+// RevokeKey stops keyID from signing requests immediately.
+func (s *APIKeyService) RevokeKey(ctx context.Context, actor, keyID string) (*model.APIKey, error) {
+	key, err := s.repo.GetAPIKey(keyID)
+	if err != nil {
+		return nil, lookupError(err, "api key")
+	}
+	now := time.Now()
+	if err := s.repo.RevokeAPIKey(keyID, now); err != nil {
+		if errors.Is(err, model.ErrStatusChanged) {
+			return nil, model.Conflict("api key is already revoked")
+		}
+		s.logger.Error("revoke api key error:", err)
+		return nil, err
+	}
+	key.Status = model.APIKeyRevoked
+	key.RevokedAt = &now
+
+	s.audit(actor, "api_key.revoked", key, nil)
+	s.logger.Infof("api key revoked: %s by %s", keyID, actor)
+	return key, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, merchantID uint) ([]model.APIKey, error) {
+	return s.repo.ListAPIKeys(merchantID)
+}
+
+// Authenticate checks a signed request: the key must be usable, the
+// timestamp within SignatureMaxSkew, the signature valid and the nonce
+// unused. The nonce is only recorded for correctly signed requests, so
+// nobody else can burn a merchant's nonces.
+func (s *APIKeyService) Authenticate(ctx context.Context, req model.SignedRequest) (*model.APIKey, error) {
+	key, err := s.repo.GetAPIKey(req.KeyID)
+	if err != nil || !key.Usable(time.Now()) {
+		return nil, model.ErrInvalidSignature
+	}
+
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, model.ErrStaleRequest
+	}
+	if skew := time.Since(time.Unix(ts, 0)); skew > SignatureMaxSkew || skew < -SignatureMaxSkew {
+		return nil, model.ErrStaleRequest
+	}
+
+	secret, err := s.secrets.Open(key.EncryptedSecret, key.KeyID)
+	if err != nil {
+		s.logger.Error("decrypt api key secret error:", err)
+		return nil, model.ErrInvalidSignature
+	}
+	expected := SignRequest(secret, CanonicalRequest(req.Method, req.Path, req.Timestamp, req.Nonce, req.BodyHash))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return nil, model.ErrInvalidSignature
+	}
+
+	if req.Nonce == "" {
+		return nil, model.ErrReplayedRequest
+	}
+	fresh, err := s.redis.SetNX(ctx, "apikey:nonce:"+key.KeyID+":"+req.Nonce, 1, 2*SignatureMaxSkew).Result()
+	if err != nil {
+		s.logger.Error("nonce cache error:", err)
+		return nil, err
+	}
+	if !fresh {
+		return nil, model.ErrReplayedRequest
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) newKey(actor string, merchantID uint) (*model.IssuedAPIKey, error) {
+	keyID, err := randomToken("mk_", 12)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken("sk_", 32)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.secrets.Seal(secret, keyID)
+	if err != nil {
+		return nil, err
+	}
+	key := &model.APIKey{
+		KeyID:           keyID,
+		MerchantID:      merchantID,
+		EncryptedSecret: encrypted,
+		Status:          model.APIKeyActive,
+		CreatedBy:       actor,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.CreateAPIKey(key); err != nil {
+		s.logger.Error("create api key error:", err)
+		return nil, storeError(err)
+	}
+	return &model.IssuedAPIKey{Key: key, Secret: secret}, nil
+}
+
+func (s *APIKeyService) audit(actor, action string, key *model.APIKey, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["merchant_id"] = key.MerchantID
+	if err := s.auditRepo.CreateAuditLog(newAuditLog(actor, action, "api_key", key.KeyID, details)); err != nil {
+		s.logger.Error("write audit log error:", err)
+	}
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type apiKeyMocks struct {
+	repo      *mocks.APIKeyRepoMock
+	merchants *mocks.MerchantRepoMock
+	audit     *mocks.AuditRepoMock
+	redis     *mocks.RedisMock
+}
+
+func setupAPIKeyService() (*apiKeyMocks, model.APIKeyService) {
+	m := &apiKeyMocks{
+		repo:      new(mocks.APIKeyRepoMock),
+		merchants: new(mocks.MerchantRepoMock),
+		audit:     new(mocks.AuditRepoMock),
+		redis:     new(mocks.RedisMock),
+	}
+	return m, service.NewAPIKeyService(m.repo, m.merchants, m.audit, m.redis, testSecrets, setupLogger())
+}
+
+var testSecrets, _ = service.NewSecretBox([]byte("0123456789abcdef0123456789abcdef"))
+
+func activeKey(secret string) *model.APIKey {
+	encrypted, _ := testSecrets.Seal(secret, "mk_1")
+	return &model.APIKey{KeyID: "mk_1", MerchantID: 7, EncryptedSecret: encrypted, Status: model.APIKeyActive}
+}
+
+func signed(secret, nonce string, at time.Time) model.SignedRequest {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	req := model.SignedRequest{
+		KeyID:     "mk_1",
+		Method:    "POST",
+		Path:      "/api/verify",
+		Timestamp: ts,
+		Nonce:     nonce,
+		BodyHash:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	req.Signature = service.SignRequest(secret,
+		service.CanonicalRequest(req.Method, req.Path, req.Timestamp, req.Nonce, req.BodyHash))
+	return req
+}
+
+func TestIssueKey_StoresSecretEncrypted(t *testing.T) {
+	m, s := setupAPIKeyService()
+	m.merchants.On("GetMerchantByID", uint(7)).Return(&model.Merchant{MerchantID: 7, Status: "active"}, nil)
+	m.repo.On("CreateAPIKey", mock.Anything).Return(nil)
+	m.audit.On("CreateAuditLog", mock.Anything).Return(nil)
+
+	issued, err := s.IssueKey(context.Background(), "admin", 7)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, issued.Secret)
+	assert.NotContains(t, issued.Key.EncryptedSecret, issued.Secret)
+	stored, err := testSecrets.Open(issued.Key.EncryptedSecret, issued.Key.KeyID)
+	assert.NoError(t, err)
+	assert.Equal(t, issued.Secret, stored)
+
+	other, _ := service.NewSecretBox(nil)
+	_, err = other.Open(issued.Key.EncryptedSecret, issued.Key.KeyID)
+	assert.Error(t, err, "the stored value must not open without the server key")
+	assert.Equal(t, model.APIKeyActive, issued.Key.Status)
+}
+
+func TestRotateKey_OldKeyExpiresAfterGrace(t *testing.T) {
+	m, s := setupAPIKeyService()
+	m.repo.On("GetAPIKey", "mk_1").Return(activeKey("secret"), nil)
+	m.repo.On("CreateAPIKey", mock.Anything).Return(nil)
+	m.repo.On("ExpireAPIKey", "mk_1", mock.MatchedBy(func(at time.Time) bool {
+		return at.After(time.Now().Add(service.APIKeyRotationGrace - time.Minute))
+	})).Return(nil)
+	m.audit.On("CreateAuditLog", mock.MatchedBy(func(e *model.AuditLog) bool {
+		return e.Action == "api_key.rotated" && e.EntityID == "mk_1"
+	})).Return(nil)
+
+	issued, err := s.RotateKey(context.Background(), "admin", "mk_1")
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, "mk_1", issued.Key.KeyID)
+	assert.Equal(t, uint(7), issued.Key.MerchantID)
+	m.repo.AssertExpectations(t)
+}
+
+func TestAuthenticate_ValidSignature(t *testing.T) {
+	m, s := setupAPIKeyService()
+	m.repo.On("GetAPIKey", "mk_1").Return(activeKey("secret"), nil)
+	m.redis.On("SetNX", mock.Anything, "apikey:nonce:mk_1:n-1", 1, 2*service.SignatureMaxSkew).Return(true, nil)
+
+	key, err := s.Authenticate(context.Background(), signed("secret", "n-1", time.Now()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), key.MerchantID)
+}
+
+func TestAuthenticate_Rejects(t *testing.T) {
+	m, s := setupAPIKeyService()
+	revoked := activeKey("secret")
+	revoked.KeyID, revoked.Status = "mk_revoked", model.APIKeyRevoked
+	m.repo.On("GetAPIKey", "mk_1").Return(activeKey("secret"), nil)
+	m.repo.On("GetAPIKey", "mk_revoked").Return(revoked, nil)
+	m.redis.On("SetNX", mock.Anything, "apikey:nonce:mk_1:seen", 1, mock.Anything).Return(false, nil)
+
+	wrongSecret := signed("other", "n-2", time.Now())
+	_, err := s.Authenticate(context.Background(), wrongSecret)
+	assert.ErrorIs(t, err, model.ErrInvalidSignature)
+
+	tampered := signed("secret", "n-3", time.Now())
+	tampered.Path = "/api/confirm"
+	_, err = s.Authenticate(context.Background(), tampered)
+	assert.ErrorIs(t, err, model.ErrInvalidSignature)
+
+	_, err = s.Authenticate(context.Background(), signed("secret", "n-4", time.Now().Add(-time.Hour)))
+	assert.ErrorIs(t, err, model.ErrStaleRequest)
+
+	_, err = s.Authenticate(context.Background(), signed("secret", "seen", time.Now()))
+	assert.ErrorIs(t, err, model.ErrReplayedRequest)
+
+	fromRevoked := signed("secret", "n-5", time.Now())
+	fromRevoked.KeyID = "mk_revoked"
+	_, err = s.Authenticate(context.Background(), fromRevoked)
+	assert.ErrorIs(t, err, model.ErrInvalidSignature)
+
+	m.redis.AssertNumberOfCalls(t, "SetNX", 1)
+}
+
+func TestRevokeKey_AlreadyRevoked(t *testing.T) {
+	m, s := setupAPIKeyService()
+	m.repo.On("GetAPIKey", "mk_1").Return(activeKey("secret"), nil)
+	m.repo.On("RevokeAPIKey", "mk_1", mock.Anything).Return(model.ErrStatusChanged)
+
+	_, err := s.RevokeKey(context.Background(), "admin", "mk_1")
+
+	assert.EqualError(t, err, "api key is already revoked")
+	m.audit.AssertNotCalled(t, "CreateAuditLog", mock.Anything)
+}
+
+func TestIssueKey_UnknownMerchant(t *testing.T) {
+	m, s := setupAPIKeyService()
+	m.merchants.On("GetMerchantByID", uint(9)).Return((*model.Merchant)(nil), gorm.ErrRecordNotFound)
+
+	_, err := s.IssueKey(context.Background(), "admin", 9)
+
+	assert.EqualError(t, err, "merchant not found")
+	m.repo.AssertNotCalled(t, "CreateAPIKey", mock.Anything)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+func newAuditLog(actor, action, entityType, entityID string, details map[string]interface{}) *model.AuditLog {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	data, _ := json.Marshal(details)
+	return &model.AuditLog{
+		AuditID:    uuid.New().String(),
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    string(data),
+		CreatedAt:  time.Now(),
+	}
+}
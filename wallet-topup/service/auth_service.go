@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	MaxLoginFailures  = 5
+	LoginLockout      = 15 * time.Minute
+	MinPasswordLength = 8
+)
+
+// dummyHash is compared against when the username does not exist, so that
+// unknown and known usernames take the same time to reject.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+var knownRoles = []string{model.RoleCustomer, model.RoleAdmin, model.RoleService}
+
+type AuthService struct {
+	credentialRepo model.CredentialRepository
+	userRepo       model.UserRepository
+	issuer         model.TokenIssuer
+	redis          RedisClient
+	revocations    *RevocationList
+	logger         logs.Logger
+}
+
+func NewAuthService(
+	credentialRepo model.CredentialRepository,
+	userRepo model.UserRepository,
+	issuer model.TokenIssuer,
+	redis RedisClient,
+	logger logs.Logger,
+) model.AuthService {
+	return &AuthService{
+		credentialRepo: credentialRepo,
+		userRepo:       userRepo,
+		issuer:         issuer,
+		redis:          redis,
+		revocations:    NewRevocationList(redis),
+		logger:         logger,
+	}
+}
+
+// Login checks a username and password and starts a session, returning its
+// first access and refresh tokens. After
+// MaxLoginFailures wrong passwords in a row the username is locked for
+// LoginLockout, whether or not the next password is right. Without a working
+// failure counter logins are refused rather than left unlimited.
+func (s *AuthService) Login(ctx context.Context, username, password string) (*model.TokenPair, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	key := "login:fail:" + username
+
+	if s.redis == nil {
+		s.logger.Warnf("login refused for %s: no failure counter configured", username)
+		return nil, model.Unavailable("login temporarily unavailable", nil)
+	}
+	failures, err := s.redis.Get(ctx, key).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.logger.Error("login failure counter error:", err)
+		return nil, model.Unavailable("login temporarily unavailable", err)
+	}
+	if failures >= MaxLoginFailures {
+		s.logger.Warnf("login refused for %s: locked out", username)
+		return nil, model.ErrAccountLocked
+	}
+
+	cred, err := s.credentialRepo.GetCredential(username)
+	hash := dummyHash
+	if err == nil {
+		hash = []byte(cred.PasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || err != nil || cred.Disabled {
+		if err := s.recordFailure(ctx, key); err != nil {
+			return nil, err
+		}
+		s.logger.Warnf("login failed for %s", username)
+		return nil, model.ErrInvalidCredentials
+	}
+
+	s.redis.Del(ctx, key)
+	pair, err := s.startSession(ctx, cred)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("login succeeded for %s", username)
+	return pair, nil
+}
+
+// Refresh trades a refresh token for a new token pair. Each refresh token
+// works once. Presenting one a second time means it was copied, so the whole
+// session is revoked and the caller has to log in again.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	if s.redis == nil || refreshToken == "" {
+		return nil, model.ErrInvalidRefreshToken
+	}
+	hash := hashRefreshToken(refreshToken)
+
+	sessionID, err := s.redis.Get(ctx, refreshKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, model.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		s.logger.Error("load refresh token error:", err)
+		return nil, err
+	}
+
+	first, err := s.redis.SetNX(ctx, refreshSpentKey(hash), 1, RefreshTokenTTL).Result()
+	if err != nil {
+		s.logger.Error("mark refresh token error:", err)
+		return nil, err
+	}
+	if !first {
+		s.logger.Warnf("refresh token reused; revoking session %s", sessionID)
+		s.endSession(ctx, sessionID)
+		return nil, model.ErrRefreshTokenReused
+	}
+
+	sess, err := s.loadSession(ctx, sessionID)
+	if err != nil {
+		return nil, model.ErrInvalidRefreshToken
+	}
+	cutoff, err := s.revocations.revokedBefore(ctx, sess.Username)
+	if err != nil {
+		s.logger.Error("load revocation cutoff error:", err)
+		return nil, err
+	}
+	if sess.CreatedAt < cutoff {
+		s.endSession(ctx, sessionID)
+		return nil, model.ErrInvalidRefreshToken
+	}
+	cred, err := s.credentialRepo.GetCredential(sess.Username)
+	if err != nil || cred.Disabled {
+		s.endSession(ctx, sessionID)
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	return s.issuePair(ctx, sessionID, sess, cred)
+}
+
+// Logout revokes the caller's access token and ends its session.
+func (s *AuthService) Logout(ctx context.Context) error {
+	p, ok := model.PrincipalFrom(ctx)
+	if !ok || s.redis == nil {
+		return model.Unauthorized("not logged in")
+	}
+	if err := s.revocations.Revoke(ctx, p.TokenID, p.ExpiresAt); err != nil {
+		s.logger.Error("revoke token error:", err)
+		return err
+	}
+	if p.SessionID != "" {
+		s.endSession(ctx, p.SessionID)
+	}
+	s.logger.Infof("logout: %s session %s", p.Subject, p.SessionID)
+	return nil
+}
+
+// LogoutAll revokes every token and session issued to the caller's subject
+// so far, on every device.
+func (s *AuthService) LogoutAll(ctx context.Context) error {
+	p, ok := model.PrincipalFrom(ctx)
+	if !ok || s.redis == nil {
+		return model.Unauthorized("not logged in")
+	}
+	if err := s.revocations.RevokeSubject(ctx, p.Subject, time.Now()); err != nil {
+		s.logger.Error("revoke subject error:", err)
+		return err
+	}
+	if err := s.revocations.Revoke(ctx, p.TokenID, p.ExpiresAt); err != nil {
+		s.logger.Error("revoke token error:", err)
+		return err
+	}
+	if p.SessionID != "" {
+		s.endSession(ctx, p.SessionID)
+	}
+	s.logger.Infof("logout of all sessions: %s", p.Subject)
+	return nil
+}
+
+// CreateCredential stores a bcrypt hash of password for username. A customer
+// login must point at an existing wallet.
+func (s *AuthService) CreateCredential(ctx context.Context, username, password string, userID *uint, roles []string) (*model.Credential, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if username == "" {
+		return nil, model.Invalid("username is required")
+	}
+	if len(password) < MinPasswordLength {
+		return nil, model.Invalid("password must be at least 8 characters")
+	}
+	if len(roles) == 0 {
+		return nil, model.Invalid("at least one role is required")
+	}
+	for _, role := range roles {
+		if !slices.Contains(knownRoles, role) {
+			return nil, model.Invalid("unknown role: " + role)
+		}
+	}
+	if slices.Contains(roles, model.RoleCustomer) {
+		if userID == nil {
+			return nil, model.Invalid("customer logins need a user_id")
+		}
+		if _, err := s.userRepo.GetUserByID(*userID); err != nil {
+			return nil, lookupError(err, "user")
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	cred := &model.Credential{
+		Username:     username,
+		PasswordHash: string(hash),
+		UserID:       userID,
+		Roles:        strings.Join(roles, ","),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	created, err := s.credentialRepo.CreateCredential(cred)
+	if err != nil {
+		s.logger.Error("create credential error:", err)
+		return nil, storeError(err)
+	}
+	if !created {
+		return nil, model.Conflict("username already exists")
+	}
+
+	s.logger.Infof("credential created: %s", username)
+	return cred, nil
+}
+
+// recordFailure counts a wrong password. If it cannot be counted the login is
+// refused as unavailable, so errors cannot be used to get around the lockout.
+func (s *AuthService) recordFailure(ctx context.Context, key string) error {
+	failures, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		s.logger.Error("login failure counter error:", err)
+		return model.Unavailable("login temporarily unavailable", err)
+	}
+	// Start the counting window on the first failure and restart it when the
+	// lock kicks in, so a lock always lasts the full LoginLockout.
+	if failures == 1 || failures == MaxLoginFailures {
+		s.redis.Expire(ctx, key, LoginLockout)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type authMocks struct {
+	creds  *mocks.CredentialRepoMock
+	users  *mocks.UserRepoMock
+	issuer *mocks.TokenIssuerMock
+	redis  *mocks.RedisMock
+}
+
+func setupAuthService() (*authMocks, model.AuthService) {
+	m := &authMocks{
+		creds:  new(mocks.CredentialRepoMock),
+		users:  new(mocks.UserRepoMock),
+		issuer: new(mocks.TokenIssuerMock),
+		redis:  new(mocks.RedisMock),
+	}
+	return m, service.NewAuthService(m.creds, m.users, m.issuer, m.redis, setupLogger())
+}
+
+func credential(password string) *model.Credential {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	userID := uint(1)
+	return &model.Credential{Username: "somchai", PasswordHash: string(hash), UserID: &userID, Roles: "customer"}
+}
+
+func TestLogin_Success(t *testing.T) {
+	m, s := setupAuthService()
+	cred := credential("correct horse")
+	m.redis.On("Get", mock.Anything, "login:fail:somchai").Return("", redis.Nil)
+	m.creds.On("GetCredential", "somchai").Return(cred, nil)
+	m.redis.On("Del", mock.Anything, []string{"login:fail:somchai"}).Return(nil)
+	m.issuer.On("IssueToken", "somchai", []string{"customer"}, cred.UserID, mock.Anything).Return(accessToken("signed"), nil)
+	m.redis.On("Set", mock.Anything, mock.MatchedBy(hasPrefix("session:")), mock.Anything, mock.Anything).Return(nil)
+	m.redis.On("Set", mock.Anything, mock.MatchedBy(hasPrefix("refresh:")), mock.Anything, mock.Anything).Return(nil)
+
+	pair, err := s.Login(context.Background(), " Somchai ", "correct horse")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "signed", pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
+}
+
+func TestLogin_WrongPasswordCountsFailure(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, "login:fail:somchai").Return("", redis.Nil)
+	m.creds.On("GetCredential", "somchai").Return(credential("correct horse"), nil)
+	m.redis.On("Incr", mock.Anything, "login:fail:somchai").Return(1, nil)
+	m.redis.On("Expire", mock.Anything, "login:fail:somchai", service.LoginLockout).Return(nil)
+
+	_, err := s.Login(context.Background(), "somchai", "wrong")
+
+	assert.ErrorIs(t, err, model.ErrInvalidCredentials)
+	m.redis.AssertExpectations(t)
+	m.issuer.AssertNotCalled(t, "IssueToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogin_UnknownUserLooksLikeWrongPassword(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, "login:fail:nobody").Return("", redis.Nil)
+	m.creds.On("GetCredential", "nobody").Return((*model.Credential)(nil), errors.New("not found"))
+	m.redis.On("Incr", mock.Anything, "login:fail:nobody").Return(2, nil)
+
+	_, err := s.Login(context.Background(), "nobody", "whatever")
+
+	assert.ErrorIs(t, err, model.ErrInvalidCredentials)
+}
+
+func TestLogin_LockedOut(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, "login:fail:somchai").Return("5", nil)
+
+	_, err := s.Login(context.Background(), "somchai", "correct horse")
+
+	assert.ErrorIs(t, err, model.ErrAccountLocked)
+	m.creds.AssertNotCalled(t, "GetCredential", mock.Anything)
+}
+
+func TestLogin_CounterDownRefused(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, "login:fail:somchai").Return("", errors.New("connection refused"))
+
+	_, err := s.Login(context.Background(), "somchai", "correct horse")
+
+	assert.Equal(t, model.KindUnavailable, model.KindOf(err))
+	m.creds.AssertNotCalled(t, "GetCredential", mock.Anything)
+}
+
+func TestLogin_FailureNotCountedRefused(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, "login:fail:somchai").Return("", redis.Nil)
+	m.creds.On("GetCredential", "somchai").Return(credential("correct horse"), nil)
+	m.redis.On("Incr", mock.Anything, "login:fail:somchai").Return(0, errors.New("connection refused"))
+
+	_, err := s.Login(context.Background(), "somchai", "wrong")
+
+	assert.Equal(t, model.KindUnavailable, model.KindOf(err))
+}
+
+func hasPrefix(prefix string) func(string) bool {
+	return func(key string) bool { return strings.HasPrefix(key, prefix) }
+}
+
+func accessToken(token string) *model.AccessToken {
+	return &model.AccessToken{Token: token, ID: "jti-" + token, ExpiresAt: time.Now().Add(15 * time.Minute)}
+}
+
+func refreshKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "refresh:" + hex.EncodeToString(sum[:])
+}
+
+func sessionJSON(createdAt time.Time) string {
+	return fmt.Sprintf(`{"username":"somchai","created_at":%d,"access_id":"jti-old","access_exp":%d}`,
+		createdAt.Unix(), time.Now().Add(10*time.Minute).Unix())
+}
+
+func TestRefresh_RotatesTokens(t *testing.T) {
+	m, s := setupAuthService()
+	cred := credential("correct horse")
+	m.redis.On("Get", mock.Anything, refreshKey("old-refresh")).Return("sess-1", nil)
+	m.redis.On("SetNX", mock.Anything, mock.MatchedBy(hasPrefix("refresh:spent:")), 1, service.RefreshTokenTTL).Return(true, nil)
+	m.redis.On("Get", mock.Anything, "session:sess-1").Return(sessionJSON(time.Now().Add(-time.Hour)), nil)
+	m.redis.On("Get", mock.Anything, "revoked:sub:somchai").Return("", redis.Nil)
+	m.creds.On("GetCredential", "somchai").Return(cred, nil)
+	m.issuer.On("IssueToken", "somchai", []string{"customer"}, cred.UserID, "sess-1").Return(accessToken("fresh"), nil)
+	m.redis.On("Set", mock.Anything, "session:sess-1", mock.Anything, mock.Anything).Return(nil)
+	m.redis.On("Set", mock.Anything, mock.MatchedBy(hasPrefix("refresh:")), "sess-1", mock.Anything).Return(nil)
+
+	pair, err := s.Refresh(context.Background(), "old-refresh")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fresh", pair.AccessToken)
+	assert.NotEqual(t, "old-refresh", pair.RefreshToken)
+}
+
+func TestRefresh_ReuseRevokesSession(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, refreshKey("old-refresh")).Return("sess-1", nil)
+	m.redis.On("SetNX", mock.Anything, mock.MatchedBy(hasPrefix("refresh:spent:")), 1, service.RefreshTokenTTL).Return(false, nil)
+	m.redis.On("Get", mock.Anything, "session:sess-1").Return(sessionJSON(time.Now().Add(-time.Hour)), nil)
+	m.redis.On("Set", mock.Anything, "revoked:jti:jti-old", 1, mock.Anything).Return(nil)
+	m.redis.On("Del", mock.Anything, []string{"session:sess-1"}).Return(nil)
+
+	_, err := s.Refresh(context.Background(), "old-refresh")
+
+	assert.ErrorIs(t, err, model.ErrRefreshTokenReused)
+	m.redis.AssertExpectations(t)
+	m.issuer.AssertNotCalled(t, "IssueToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_UnknownToken(t *testing.T) {
+	m, s := setupAuthService()
+	m.redis.On("Get", mock.Anything, refreshKey("bogus")).Return("", redis.Nil)
+
+	_, err := s.Refresh(context.Background(), "bogus")
+
+	assert.ErrorIs(t, err, model.ErrInvalidRefreshToken)
+}
+
+func TestLogout_RevokesTokenAndSession(t *testing.T) {
+	m, s := setupAuthService()
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject:   "somchai",
+		TokenID:   "jti-current",
+		SessionID: "sess-1",
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	})
+	m.redis.On("Set", mock.Anything, "revoked:jti:jti-current", 1, mock.Anything).Return(nil)
+	m.redis.On("Get", mock.Anything, "session:sess-1").Return(sessionJSON(time.Now()), nil)
+	m.redis.On("Set", mock.Anything, "revoked:jti:jti-old", 1, mock.Anything).Return(nil)
+	m.redis.On("Del", mock.Anything, []string{"session:sess-1"}).Return(nil)
+
+	assert.NoError(t, s.Logout(ctx))
+	m.redis.AssertExpectations(t)
+}
+
+func TestLogoutAll_SetsSubjectCutoff(t *testing.T) {
+	m, s := setupAuthService()
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject:   "somchai",
+		TokenID:   "jti-current",
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	})
+	m.redis.On("Set", mock.Anything, "revoked:sub:somchai", mock.Anything, service.RefreshTokenTTL).Return(nil)
+	m.redis.On("Set", mock.Anything, "revoked:jti:jti-current", 1, mock.Anything).Return(nil)
+
+	assert.NoError(t, s.LogoutAll(ctx))
+	m.redis.AssertExpectations(t)
+}
+
+func TestRevocationList_IsRevoked(t *testing.T) {
+	r := new(mocks.RedisMock)
+	list := service.NewRevocationList(r)
+	r.On("Get", mock.Anything, "revoked:jti:revoked").Return("1", nil)
+	r.On("Get", mock.Anything, "revoked:jti:live").Return("", redis.Nil)
+	r.On("Get", mock.Anything, "revoked:sub:somchai").Return(strconv.FormatInt(time.Now().Unix(), 10), nil)
+
+	revoked, err := list.IsRevoked(context.Background(), &model.Principal{Subject: "somchai", TokenID: "revoked"})
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = list.IsRevoked(context.Background(), &model.Principal{Subject: "somchai", TokenID: "live", IssuedAt: time.Now().Add(-time.Hour)})
+	assert.NoError(t, err)
+	assert.True(t, revoked, "issued before logout-all")
+
+	revoked, err = list.IsRevoked(context.Background(), &model.Principal{Subject: "somchai", TokenID: "live", IssuedAt: time.Now().Add(time.Second)})
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestCreateCredential_HashesPassword(t *testing.T) {
+	m, s := setupAuthService()
+	userID := uint(1)
+	m.users.On("GetUserByID", userID).Return(&model.User{UserID: 1}, nil)
+	m.creds.On("CreateCredential", mock.Anything).Return(true, nil)
+
+	cred, err := s.CreateCredential(context.Background(), "Somchai", "correct horse", &userID, []string{model.RoleCustomer})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "somchai", cred.Username)
+	assert.NotEqual(t, "correct horse", cred.PasswordHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(cred.PasswordHash), []byte("correct horse")))
+}
+
+func TestCreateCredential_CustomerNeedsUser(t *testing.T) {
+	m, s := setupAuthService()
+
+	_, err := s.CreateCredential(context.Background(), "somchai", "correct horse", nil, []string{model.RoleCustomer})
+
+	assert.EqualError(t, err, "customer logins need a user_id")
+	m.creds.AssertNotCalled(t, "CreateCredential", mock.Anything)
+}
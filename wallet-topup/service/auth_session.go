@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+// RefreshTokenTTL is how long a login session lasts. Refreshing rotates the
+// tokens but does not extend the session.
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// authSession is a login session, stored in Redis under its ID. It remembers
+// the latest access token so that ending the session can revoke it.
+type authSession struct {
+	Username  string `json:"username"`
+	CreatedAt int64  `json:"created_at"`
+	AccessID  string `json:"access_id"`
+	AccessExp int64  `json:"access_exp"`
+}
+
+func sessionKey(id string) string {
+	return "session:" + id
+}
+
+// Refresh tokens are stored by hash. The token key points at its session and
+// lives as long as the session, so a second use can be recognised; the spent
+// key marks it as used.
+func refreshKey(hash string) string {
+	return "refresh:" + hash
+}
+
+func refreshSpentKey(hash string) string {
+	return "refresh:spent:" + hash
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *AuthService) startSession(ctx context.Context, cred *model.Credential) (*model.TokenPair, error) {
+	if s.redis == nil {
+		return nil, model.Unavailable("session store unavailable", nil)
+	}
+	sess := &authSession{Username: cred.Username, CreatedAt: time.Now().Unix()}
+	return s.issuePair(ctx, uuid.NewString(), sess, cred)
+}
+
+// issuePair signs a new access token and refresh token for the session and
+// saves both. They expire with the session.
+func (s *AuthService) issuePair(ctx context.Context, sessionID string, sess *authSession, cred *model.Credential) (*model.TokenPair, error) {
+	ttl := time.Until(time.Unix(sess.CreatedAt, 0).Add(RefreshTokenTTL))
+	if ttl <= 0 {
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	access, err := s.issuer.IssueToken(cred.Username, cred.RoleList(), cred.UserID, sessionID)
+	if err != nil {
+		s.logger.Error("issue token error:", err)
+		return nil, err
+	}
+	refresh, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	sess.AccessID = access.ID
+	sess.AccessExp = access.ExpiresAt.Unix()
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.Set(ctx, sessionKey(sessionID), data, ttl).Err(); err != nil {
+		s.logger.Error("save session error:", err)
+		return nil, err
+	}
+	if err := s.redis.Set(ctx, refreshKey(hashRefreshToken(refresh)), sessionID, ttl).Err(); err != nil {
+		s.logger.Error("save refresh token error:", err)
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  access.Token,
+		RefreshToken: refresh,
+		ExpiresAt:    access.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) loadSession(ctx context.Context, sessionID string) (*authSession, error) {
+	data, err := s.redis.Get(ctx, sessionKey(sessionID)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var sess authSession
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// endSession deletes the session, which makes all of its refresh tokens
+// useless, and revokes its latest access token.
+func (s *AuthService) endSession(ctx context.Context, sessionID string) {
+	sess, err := s.loadSession(ctx, sessionID)
+	if err != nil {
+		return
+	}
+	if err := s.revocations.Revoke(ctx, sess.AccessID, time.Unix(sess.AccessExp, 0)); err != nil {
+		s.logger.Error("revoke token error:", err)
+	}
+	if err := s.redis.Del(ctx, sessionKey(sessionID)).Err(); err != nil {
+		s.logger.Error("end session error:", err)
+	}
+}
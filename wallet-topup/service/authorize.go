@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+)
+
+// authorizeWallet lets a request act on userID's wallet if the caller owns it
+// or holds an admin or service role. Acting for someone else is logged.
+// Background jobs must say so with model.WithSystem; a call without any
+// principal is refused. It reports whether the caller acted on behalf of
+// another user.
+func authorizeWallet(ctx context.Context, logger logs.Logger, userID uint, action string) (bool, error) {
+	p, ok := model.PrincipalFrom(ctx)
+	if !ok {
+		logger.Warnf("%s denied: no caller for user_id=%d", action, userID)
+		return false, model.ErrUnauthenticated
+	}
+	if p.Owns(userID) || p.IsSystem() {
+		return false, nil
+	}
+	if !p.Privileged() {
+		logger.Warnf("%s denied: %s tried to act on user_id=%d", action, p.Subject, userID)
+		return false, model.ErrForbidden
+	}
+	logger.Infof("%s by %s %v on behalf of user_id=%d", action, p.Subject, p.Roles, userID)
+	return true, nil
+}
+
+// authorizeQuote lets a request redeem a quote for the wallet it was issued
+// for. Besides the callers authorizeWallet allows, a merchant API key may
+// redeem it: only the customer or a privileged caller can obtain a quote, so
+// a merchant holding its token has the customer's consent to a top-up on
+// exactly those terms. It reports whether the caller acted on behalf of
+// another user.
+func authorizeQuote(ctx context.Context, logger logs.Logger, quote *model.Quote) (bool, error) {
+	if p, ok := model.PrincipalFrom(ctx); ok && p.IsMerchant() {
+		logger.Infof("verify by %s with quote %s for user_id=%d", p.Subject, quote.QuoteID, quote.UserID)
+		return true, nil
+	}
+	return authorizeWallet(ctx, logger, quote.UserID, "verify")
+}
+
+// authorizeMerchant lets a request act on merchantID's payments if it was
+// signed with one of that merchant's API keys or comes from an admin,
+// service or background job.
+func authorizeMerchant(ctx context.Context, logger logs.Logger, merchantID uint, action string) error {
+	p, ok := model.PrincipalFrom(ctx)
+	if !ok {
+		logger.Warnf("%s denied: no caller for merchant_id=%d", action, merchantID)
+		return model.ErrUnauthenticated
+	}
+	if p.MerchantID != nil && *p.MerchantID == merchantID {
+		return nil
+	}
+	if !p.Privileged() {
+		logger.Warnf("%s denied: %s tried to act on merchant_id=%d", action, p.Subject, merchantID)
+		return model.ErrForbidden
+	}
+	logger.Infof("%s by %s %v on behalf of merchant_id=%d", action, p.Subject, p.Roles, merchantID)
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultAutoTopUpDailyLimit = 5000.00
+	MaxAutoTopUpDailyLimit     = 50000.00
+
+	// autoTopUpLockTTL bounds how long a crashed worker can block the next
+	// auto top-up for the same user.
+	autoTopUpLockTTL = 5 * time.Minute
+)
+
+type AutoTopUpService struct {
+	repo     model.AutoTopUpRepository
+	userRepo model.UserRepository
+	wallet   model.WalletService
+	payments model.PaymentProvider
+	redis    RedisClient
+	logger   logs.Logger
+}
+
+func NewAutoTopUpService(
+	repo model.AutoTopUpRepository,
+	userRepo model.UserRepository,
+	wallet model.WalletService,
+	payments model.PaymentProvider,
+	redis RedisClient,
+	logger logs.Logger,
+) model.AutoTopUpService {
+	return &AutoTopUpService{
+		repo:     repo,
+		userRepo: userRepo,
+		wallet:   wallet,
+		payments: payments,
+		redis:    redis,
+		logger:   logger,
+	}
+}
+
+func (s *AutoTopUpService) SaveConfig(ctx context.Context, cfg *model.AutoTopUpConfig) (*model.AutoTopUpConfig, error) {
+	if _, err := authorizeWallet(ctx, s.logger, cfg.UserID, "configure auto top-up"); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetUserByID(cfg.UserID); err != nil {
+		return nil, lookupError(err, "user")
+	}
+	if cfg.Threshold < 0 {
+		return nil, model.Invalid("threshold must not be negative")
+	}
+	if cfg.Amount <= 0 {
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if cfg.PaymentToken == "" {
+		return nil, model.Invalid("payment token is required")
+	}
+	if cfg.DailyLimit == 0 {
+		cfg.DailyLimit = DefaultAutoTopUpDailyLimit
+	}
+	if cfg.DailyLimit < cfg.Amount || cfg.DailyLimit > MaxAutoTopUpDailyLimit {
+		return nil, model.Invalid("daily limit is out of range")
+	}
+
+	now := time.Now()
+	if existing, err := s.repo.GetConfig(cfg.UserID); err == nil {
+		cfg.CreatedAt = existing.CreatedAt
+	} else {
+		cfg.CreatedAt = now
+	}
+	cfg.UpdatedAt = now
+
+	if err := s.repo.SaveConfig(cfg); err != nil {
+		s.logger.Error("failed to save auto top-up config:", err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("auto top-up configured for user_id=%d", cfg.UserID)
+	return cfg, nil
+}
+
+func (s *AutoTopUpService) GetConfig(ctx context.Context, userID uint) (*model.AutoTopUpConfig, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "read auto top-up"); err != nil {
+		return nil, err
+	}
+	cfg, err := s.repo.GetConfig(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NotFound("auto top-up is not configured")
+	}
+	if err != nil {
+		return nil, lookupError(err, "auto top-up")
+	}
+	return cfg, nil
+}
+
+// BalanceDecreased checks the wallet in the background so the debit that
+// triggered it is not held up by the payment provider.
+func (s *AutoTopUpService) BalanceDecreased(userID uint) {
+	go func() {
+		if err := s.CheckBalance(model.WithSystem(context.Background(), "auto-topup"), userID); err != nil {
+			s.logger.Warnf("auto top-up for user_id=%d: %v", userID, err)
+		}
+	}()
+}
+
+// CheckBalance tops the wallet up when auto top-up is enabled and the balance
+// is below the configured threshold. A Redis lock keeps at most one auto
+// top-up in flight per user.
+func (s *AutoTopUpService) CheckBalance(ctx context.Context, userID uint) error {
+	cfg, err := s.repo.GetConfig(userID)
+	if err != nil || !cfg.Enabled {
+		return nil
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return lookupError(err, "user")
+	}
+	if user.Balance >= cfg.Threshold {
+		return nil
+	}
+
+	lockKey := fmt.Sprintf("autotopup:%d", userID)
+	acquired, err := s.redis.SetNX(ctx, lockKey, "1", autoTopUpLockTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		s.logger.Infof("auto top-up already in flight for user_id=%d", userID)
+		return nil
+	}
+	defer s.redis.Del(ctx, lockKey)
+
+	now := time.Now()
+	total, err := s.repo.SumSucceededSince(userID, startOfDay(now))
+	if err != nil {
+		return err
+	}
+	if total+cfg.Amount > cfg.DailyLimit {
+		return model.LimitExceeded("daily auto top-up limit reached")
+	}
+
+	txn, err := chargeTopUp(ctx, s.wallet, s.payments, userID, cfg.Amount, cfg.PaymentMethod, cfg.PaymentToken)
+	if isUncredited(err) {
+		// Nothing retries an auto top-up, so the charge is settled right away.
+		s.logger.Warnf("auto top-up for user_id=%d: %v", userID, err)
+		txn, err = settleCharge(ctx, s.wallet, s.payments, txn.TransactionID)
+	}
+	topUp := &model.AutoTopUp{
+		AutoTopUpID: uuid.New().String(),
+		UserID:      userID,
+		Amount:      cfg.Amount,
+		Status:      model.RunSucceeded,
+		CreatedAt:   now,
+	}
+	if txn != nil {
+		topUp.TransactionID = txn.TransactionID
+	}
+	if err != nil {
+		topUp.Status = model.RunFailed
+		topUp.Error = err.Error()
+	}
+	if recordErr := s.repo.CreateAutoTopUp(topUp); recordErr != nil {
+		s.logger.Error("failed to record auto top-up:", recordErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("auto top-up completed for user_id=%d: %s", userID, topUp.TransactionID)
+	return nil
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type autoTopUpMocks struct {
+	repo     *mocks.AutoTopUpRepoMock
+	users    *mocks.UserRepoMock
+	wallet   *mocks.WalletServiceMock
+	payments *mocks.PaymentProviderMock
+	redis    *mocks.RedisMock
+}
+
+func setupAutoTopUpService() (*autoTopUpMocks, model.AutoTopUpService) {
+	m := &autoTopUpMocks{
+		repo:     new(mocks.AutoTopUpRepoMock),
+		users:    new(mocks.UserRepoMock),
+		wallet:   new(mocks.WalletServiceMock),
+		payments: new(mocks.PaymentProviderMock),
+		redis:    new(mocks.RedisMock),
+	}
+	m.repo.On("GetConfig", uint(1)).Return(&model.AutoTopUpConfig{
+		UserID: 1, Threshold: 100, Amount: 500, DailyLimit: 1000,
+		PaymentMethod: "credit_card", PaymentToken: "tok_1", Enabled: true,
+	}, nil)
+	s := service.NewAutoTopUpService(m.repo, m.users, m.wallet, m.payments, m.redis, setupLogger())
+	return m, s
+}
+
+func TestCheckBalance_TopsUpBelowThreshold(t *testing.T) {
+	m, s := setupAutoTopUpService()
+	m.users.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 40}, nil)
+	m.redis.On("SetNX", mock.Anything, "autotopup:1", mock.Anything, mock.Anything).Return(true, nil)
+	m.redis.On("Del", mock.Anything, []string{"autotopup:1"}).Return(nil)
+	m.repo.On("SumSucceededSince", uint(1), mock.Anything).Return(0.0, nil)
+	m.wallet.On("VerifyTransaction", mock.Anything, uint(1), 500.0, "credit_card").Return(&model.Transaction{TransactionID: "t1"}, nil)
+	m.payments.On("Charge", mock.Anything, uint(1), 500.0, "credit_card", "tok_1", "t1").Return("charge_1", nil)
+	m.wallet.On("ConfirmTransaction", mock.Anything, "t1").Return(&model.Transaction{TransactionID: "t1", Status: "completed"}, nil)
+	m.repo.On("CreateAutoTopUp", mock.Anything).Return(nil)
+
+	err := s.CheckBalance(systemCtx(), 1)
+
+	assert.NoError(t, err)
+	m.wallet.AssertCalled(t, "ConfirmTransaction", mock.Anything, "t1")
+	m.redis.AssertCalled(t, "Del", mock.Anything, []string{"autotopup:1"})
+	record := m.repo.Calls[2].Arguments.Get(0).(*model.AutoTopUp)
+	assert.Equal(t, model.RunSucceeded, record.Status)
+}
+
+func TestCheckBalance_AboveThreshold(t *testing.T) {
+	m, s := setupAutoTopUpService()
+	m.users.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 100}, nil)
+
+	err := s.CheckBalance(systemCtx(), 1)
+
+	assert.NoError(t, err)
+	m.redis.AssertNotCalled(t, "SetNX", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckBalance_AlreadyInFlight(t *testing.T) {
+	m, s := setupAutoTopUpService()
+	m.users.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 40}, nil)
+	m.redis.On("SetNX", mock.Anything, "autotopup:1", mock.Anything, mock.Anything).Return(false, nil)
+
+	err := s.CheckBalance(systemCtx(), 1)
+
+	assert.NoError(t, err)
+	m.wallet.AssertNotCalled(t, "VerifyTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	m.redis.AssertNotCalled(t, "Del", mock.Anything, mock.Anything)
+}
+
+func TestCheckBalance_DailyLimitReached(t *testing.T) {
+	m, s := setupAutoTopUpService()
+	m.users.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 40}, nil)
+	m.redis.On("SetNX", mock.Anything, "autotopup:1", mock.Anything, mock.Anything).Return(true, nil)
+	m.redis.On("Del", mock.Anything, []string{"autotopup:1"}).Return(nil)
+	m.repo.On("SumSucceededSince", uint(1), mock.Anything).Return(600.0, nil)
+
+	err := s.CheckBalance(systemCtx(), 1)
+
+	assert.EqualError(t, err, "daily auto top-up limit reached")
+	m.wallet.AssertNotCalled(t, "VerifyTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSaveConfig_Validation(t *testing.T) {
+	m, s := setupAutoTopUpService()
+	m.users.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
+
+	_, err := s.SaveConfig(systemCtx(), &model.AutoTopUpConfig{UserID: 1, Threshold: 100, Amount: 500, PaymentToken: "tok_1", DailyLimit: 100})
+	assert.EqualError(t, err, "daily limit is out of range")
+
+	m.repo.On("SaveConfig", mock.Anything).Return(nil)
+	cfg, err := s.SaveConfig(systemCtx(), &model.AutoTopUpConfig{UserID: 1, Threshold: 100, Amount: 500, PaymentToken: "tok_1"})
+	assert.NoError(t, err)
+	assert.Equal(t, service.DefaultAutoTopUpDailyLimit, cfg.DailyLimit)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"slices"
+	"wallet-topup/model"
+)
+
+// WatchBalances wraps txManager so that every wallet whose balance goes down
+// inside a committed transaction is reported to observer. Debits from any
+// service (payments, transfers, hold captures, paid withdrawals, adjustments)
+// are seen here, so none of them has to remember to notify.
+func WatchBalances(txManager model.TxManager, observer model.BalanceObserver) model.TxManager {
+	return &balanceWatch{txManager: txManager, observer: observer}
+}
+
+type balanceWatch struct {
+	txManager model.TxManager
+	observer  model.BalanceObserver
+}
+
+func (w *balanceWatch) WithinTransaction(fn func(repos model.TxRepositories) error) error {
+	var debited []uint
+	err := w.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		debited = debited[:0]
+		repos.Users = &debitRecorder{UserRepository: repos.Users, debited: &debited}
+		return fn(repos)
+	})
+	if err != nil {
+		return err
+	}
+	for _, userID := range debited {
+		w.observer.BalanceDecreased(userID)
+	}
+	return nil
+}
+
+// debitRecorder notes which users a transaction debits.
+type debitRecorder struct {
+	model.UserRepository
+	debited *[]uint
+}
+
+func (r *debitRecorder) DebitUserBalance(userID uint, amount float64) error {
+	if err := r.UserRepository.DebitUserBalance(userID, amount); err != nil {
+		return err
+	}
+	r.record(userID)
+	return nil
+}
+
+func (r *debitRecorder) UpdateUserBalance(userID uint, amount float64) error {
+	if err := r.UserRepository.UpdateUserBalance(userID, amount); err != nil {
+		return err
+	}
+	if amount < 0 {
+		r.record(userID)
+	}
+	return nil
+}
+
+func (r *debitRecorder) record(userID uint) {
+	if !slices.Contains(*r.debited, userID) {
+		*r.debited = append(*r.debited, userID)
+	}
+}
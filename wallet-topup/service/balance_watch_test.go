@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observedDebits []uint
+
+func (o *observedDebits) BalanceDecreased(userID uint) {
+	*o = append(*o, userID)
+}
+
+func TestWatchBalances_ReportsCommittedDebits(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	userRepo.On("DebitUserBalance", uint(1), 50.0).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(1), -20.0).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(2), 70.0).Return(nil)
+	var observed observedDebits
+	txManager := service.WatchBalances(&mocks.TxManagerMock{Repos: model.TxRepositories{Users: userRepo}}, &observed)
+
+	err := txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Users.DebitUserBalance(1, 50); err != nil {
+			return err
+		}
+		if err := repos.Users.UpdateUserBalance(1, -20); err != nil {
+			return err
+		}
+		return repos.Users.UpdateUserBalance(2, 70)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, observedDebits{1}, observed)
+}
+
+func TestWatchBalances_IgnoresRolledBackDebits(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	userRepo.On("DebitUserBalance", uint(1), 50.0).Return(nil)
+	var observed observedDebits
+	txManager := service.WatchBalances(&mocks.TxManagerMock{Repos: model.TxRepositories{Users: userRepo}}, &observed)
+
+	err := txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Users.DebitUserBalance(1, 50); err != nil {
+			return err
+		}
+		return errors.New("ledger write failed")
+	})
+
+	assert.Error(t, err)
+	assert.Empty(t, observed)
+}
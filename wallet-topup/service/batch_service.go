@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxBatchRows = 5000
+
+	batchRowChunk         = 100
+	unfinishedBatchLimit  = 10
+	batchRowErrorsToTrack = 100
+)
+
+var batchHeader = []string{"user_id", "amount", "reference"}
+
+type BatchService struct {
+	txManager model.TxManager
+	batchRepo model.BatchRepository
+	userRepo  model.UserRepository
+	logger    logs.Logger
+}
+
+func NewBatchService(
+	txManager model.TxManager,
+	batchRepo model.BatchRepository,
+	userRepo model.UserRepository,
+	logger logs.Logger,
+) model.BatchService {
+	return &BatchService{
+		txManager: txManager,
+		batchRepo: batchRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+	}
+}
+
+// SubmitBatch validates every CSV row and stores the batch for processing.
+// If a batch with the same reference already exists it is returned unchanged
+// with created set to false.
+func (s *BatchService) SubmitBatch(ctx context.Context, reference string, file io.Reader) (*model.Batch, bool, error) {
+	if reference == "" {
+		return nil, false, model.Invalid("batch reference is required")
+	}
+	if existing, err := s.batchRepo.GetBatchByReference(reference); err == nil {
+		s.logger.Infof("batch resubmitted: %s", reference)
+		return existing, false, nil
+	}
+
+	rows, err := s.parseRows(file)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	batch := &model.Batch{
+		BatchID:   uuid.New().String(),
+		Reference: reference,
+		Status:    model.BatchPending,
+		TotalRows: len(rows),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i := range rows {
+		rows[i].BatchID = batch.BatchID
+		rows[i].UpdatedAt = now
+		batch.TotalAmount += rows[i].Amount
+	}
+
+	if err := s.batchRepo.CreateBatch(batch, rows); err != nil {
+		// Lost a race with a concurrent submission of the same reference.
+		if existing, lookupErr := s.batchRepo.GetBatchByReference(reference); lookupErr == nil {
+			return existing, false, nil
+		}
+		s.logger.Error("failed to create batch:", err)
+		return nil, false, storeError(err)
+	}
+
+	s.logger.Infof("batch created: %s (%d rows)", batch.BatchID, batch.TotalRows)
+	return batch, true, nil
+}
+
+func (s *BatchService) GetBatch(ctx context.Context, batchID string) (*model.Batch, error) {
+	batch, err := s.batchRepo.GetBatchByID(batchID)
+	if err != nil {
+		return nil, lookupError(err, "batch")
+	}
+	return batch, nil
+}
+
+func (s *BatchService) ListRows(ctx context.Context, batchID string) ([]model.BatchRow, error) {
+	if _, err := s.GetBatch(ctx, batchID); err != nil {
+		return nil, err
+	}
+	return s.batchRepo.ListRows(batchID)
+}
+
+// ProcessBatch credits every pending row of the batch. Each row is credited in
+// its own transaction together with its status change, so a row is never
+// credited twice even if processing is interrupted and resumed.
+func (s *BatchService) ProcessBatch(ctx context.Context, batchID string) error {
+	for {
+		rows, err := s.batchRepo.ListPendingRows(batchID, batchRowChunk)
+		if err != nil {
+			s.logger.Error("list batch rows error:", err)
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			s.creditRow(row)
+		}
+		if _, err := s.batchRepo.RefreshBatchProgress(batchID); err != nil {
+			s.logger.Error("update batch progress error:", err)
+			return err
+		}
+	}
+
+	batch, err := s.batchRepo.RefreshBatchProgress(batchID)
+	if err != nil {
+		s.logger.Error("update batch progress error:", err)
+		return err
+	}
+	s.logger.Infof("batch %s %s: %d credited, %d failed", batchID, batch.Status, batch.SucceededRows, batch.FailedRows)
+	return nil
+}
+
+// ResumeUnfinishedBatches picks up batches left pending or half-processed,
+// e.g. after a restart.
+func (s *BatchService) ResumeUnfinishedBatches(ctx context.Context) error {
+	batches, err := s.batchRepo.ListUnfinishedBatches(unfinishedBatchLimit)
+	if err != nil {
+		s.logger.Error("list unfinished batches error:", err)
+		return err
+	}
+	for _, b := range batches {
+		if err := s.ProcessBatch(ctx, b.BatchID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// creditRow credits one row. The wallet must still be active and within its
+// KYC tier limits when the row is processed; otherwise the row fails with the
+// reason and the rest of the batch carries on.
+func (s *BatchService) creditRow(row model.BatchRow) {
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Batches.UpdateRowStatus(row.BatchID, row.RowNumber, model.BatchRowPending, model.BatchRowCredited, ""); err != nil {
+			return err
+		}
+		user, err := repos.Users.GetUserByIDForUpdate(row.UserID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		if err := user.CheckActive(); err != nil {
+			return err
+		}
+		if err := checkTierCaps(user, row.Amount); err != nil {
+			return err
+		}
+		if err := repos.Users.UpdateUserBalance(row.UserID, row.Amount); err != nil {
+			return err
+		}
+		return repos.Ledger.CreateEntries(&model.LedgerEntry{
+			EntryID:   uuid.New().String(),
+			UserID:    row.UserID,
+			Amount:    row.Amount,
+			Type:      "bulk_credit",
+			Reference: fmt.Sprintf("%s:%d", row.BatchID, row.RowNumber),
+			CreatedAt: time.Now(),
+		})
+	})
+	if err == nil || errors.Is(err, model.ErrStatusChanged) {
+		return
+	}
+
+	s.logger.Warnf("batch %s row %d failed: %v", row.BatchID, row.RowNumber, err)
+	if err := s.batchRepo.UpdateRowStatus(row.BatchID, row.RowNumber, model.BatchRowPending, model.BatchRowFailed, err.Error()); err != nil {
+		s.logger.Error("mark batch row failed error:", err)
+	}
+}
+
+// parseRows reads and validates the whole CSV before anything is stored. Row
+// numbers count data rows from 1, excluding the header.
+func (s *BatchService) parseRows(file io.Reader) ([]model.BatchRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = len(batchHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, model.Invalid("csv file is empty or malformed")
+	}
+	for i, col := range batchHeader {
+		if strings.ToLower(strings.TrimSpace(header[i])) != col {
+			return nil, model.Invalid(fmt.Sprintf("csv header must be %s", strings.Join(batchHeader, ",")))
+		}
+	}
+
+	var rows []model.BatchRow
+	var rowErrors []model.BatchRowError
+	addError := func(row int, msg string) {
+		if len(rowErrors) < batchRowErrorsToTrack {
+			rowErrors = append(rowErrors, model.BatchRowError{RowNumber: row, Error: msg})
+		}
+	}
+	knownUsers := make(map[uint]*model.User)
+	planned := make(map[uint]float64)
+	seenRefs := make(map[string]int)
+
+	for rowNumber := 1; ; rowNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if rowNumber > MaxBatchRows {
+			return nil, model.Invalid(fmt.Sprintf("batch exceeds %d rows", MaxBatchRows))
+		}
+		if err != nil {
+			addError(rowNumber, "malformed row")
+			continue
+		}
+
+		userID, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil || userID == 0 {
+			addError(rowNumber, "invalid user_id")
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			addError(rowNumber, "invalid amount")
+			continue
+		}
+		if amount <= 0 {
+			addError(rowNumber, "amount must be greater than zero")
+			continue
+		}
+		if amount > MaxTopUpAmount {
+			addError(rowNumber, "amount exceeds maximum allowed")
+			continue
+		}
+		ref := strings.TrimSpace(record[2])
+		if ref == "" {
+			addError(rowNumber, "reference is required")
+			continue
+		}
+		if first, ok := seenRefs[ref]; ok {
+			addError(rowNumber, fmt.Sprintf("duplicate reference (row %d)", first))
+			continue
+		}
+		seenRefs[ref] = rowNumber
+
+		user, checked := knownUsers[uint(userID)]
+		if !checked {
+			user, _ = s.userRepo.GetUserByID(uint(userID))
+			knownUsers[uint(userID)] = user
+		}
+		if user == nil {
+			addError(rowNumber, "user not found")
+			continue
+		}
+		if err := user.CheckActive(); err != nil {
+			addError(rowNumber, err.Error())
+			continue
+		}
+		// Earlier rows for the same user count towards the balance limit.
+		projected := *user
+		projected.Balance += planned[uint(userID)]
+		if err := checkTierCaps(&projected, amount); err != nil {
+			addError(rowNumber, err.Error())
+			continue
+		}
+		planned[uint(userID)] += amount
+
+		rows = append(rows, model.BatchRow{
+			RowNumber: rowNumber,
+			UserID:    uint(userID),
+			Amount:    amount,
+			Reference: ref,
+			Status:    model.BatchRowPending,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, &model.BatchValidationError{Rows: rowErrors}
+	}
+	if len(rows) == 0 {
+		return nil, model.Invalid("csv file has no rows")
+	}
+	return rows, nil
+}
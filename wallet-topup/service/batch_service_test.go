@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupBatchService() (*mocks.BatchRepoMock, *mocks.UserRepoMock, *mocks.LedgerRepoMock, model.BatchService) {
+	batchRepo := new(mocks.BatchRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	ledgerRepo := new(mocks.LedgerRepoMock)
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:   userRepo,
+		Ledger:  ledgerRepo,
+		Batches: batchRepo,
+	}}
+	return batchRepo, userRepo, ledgerRepo, service.NewBatchService(txManager, batchRepo, userRepo, setupLogger())
+}
+
+func TestSubmitBatch_Success(t *testing.T) {
+	batchRepo, userRepo, _, s := setupBatchService()
+	batchRepo.On("GetBatchByReference", "payroll-2025-01").Return((*model.Batch)(nil), errors.New("not found"))
+	userRepo.On("GetUserByID", mock.Anything).Return(&model.User{}, nil)
+	batchRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(nil)
+
+	csv := "user_id,amount,reference\n1,1500.00,emp-1\n2,2500.50,emp-2\n1,100,emp-3\n"
+	batch, created, err := s.SubmitBatch(context.Background(), "payroll-2025-01", strings.NewReader(csv))
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 3, batch.TotalRows)
+	assert.Equal(t, 4100.50, batch.TotalAmount)
+	assert.Equal(t, model.BatchPending, batch.Status)
+	userRepo.AssertNumberOfCalls(t, "GetUserByID", 2)
+}
+
+func TestSubmitBatch_ResubmitReturnsExisting(t *testing.T) {
+	batchRepo, _, _, s := setupBatchService()
+	existing := &model.Batch{BatchID: "b1", Reference: "payroll-2025-01", Status: model.BatchCompleted}
+	batchRepo.On("GetBatchByReference", "payroll-2025-01").Return(existing, nil)
+
+	batch, created, err := s.SubmitBatch(context.Background(), "payroll-2025-01", strings.NewReader("user_id,amount,reference\n1,10,a\n"))
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "b1", batch.BatchID)
+	batchRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestSubmitBatch_ReportsEveryInvalidRow(t *testing.T) {
+	batchRepo, userRepo, _, s := setupBatchService()
+	batchRepo.On("GetBatchByReference", "b").Return((*model.Batch)(nil), errors.New("not found"))
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{}, nil)
+	userRepo.On("GetUserByID", uint(9)).Return((*model.User)(nil), errors.New("not found"))
+	userRepo.On("GetUserByID", uint(3)).Return(&model.User{UserID: 3, Status: model.WalletClosed}, nil)
+
+	csv := "user_id,amount,reference\n1,100,a\n9,100,b\n1,-5,c\n1,200000,d\nx,1,e\n1,100,a\n3,10,f\n1,4000,g\n1,1000,h\n"
+	_, _, err := s.SubmitBatch(context.Background(), "b", strings.NewReader(csv))
+
+	var validationErr *model.BatchValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []model.BatchRowError{
+		{RowNumber: 2, Error: "user not found"},
+		{RowNumber: 3, Error: "amount must be greater than zero"},
+		{RowNumber: 4, Error: "amount exceeds maximum allowed"},
+		{RowNumber: 5, Error: "invalid user_id"},
+		{RowNumber: 6, Error: "duplicate reference (row 1)"},
+		{RowNumber: 7, Error: model.ErrWalletClosed.Error()},
+		{RowNumber: 9, Error: "top-up would exceed kyc tier balance limit"},
+	}, validationErr.Rows)
+	batchRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestProcessBatch_PartialFailure(t *testing.T) {
+	batchRepo, userRepo, ledgerRepo, s := setupBatchService()
+	rows := []model.BatchRow{
+		{BatchID: "b1", RowNumber: 1, UserID: 1, Amount: 100, Status: model.BatchRowPending},
+		{BatchID: "b1", RowNumber: 2, UserID: 2, Amount: 200, Status: model.BatchRowPending},
+	}
+	batchRepo.On("ListPendingRows", "b1", mock.Anything).Return(rows, nil).Once()
+	batchRepo.On("ListPendingRows", "b1", mock.Anything).Return([]model.BatchRow{}, nil)
+	batchRepo.On("UpdateRowStatus", "b1", mock.Anything, model.BatchRowPending, model.BatchRowCredited, "").Return(nil)
+	userRepo.On("GetUserByIDForUpdate", mock.Anything).Return(&model.User{}, nil)
+	userRepo.On("UpdateUserBalance", uint(1), 100.0).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(2), 200.0).Return(errors.New("db down"))
+	ledgerRepo.On("CreateEntries", mock.Anything).Return(nil)
+	batchRepo.On("UpdateRowStatus", "b1", 2, model.BatchRowPending, model.BatchRowFailed, "db down").Return(nil)
+	batchRepo.On("RefreshBatchProgress", "b1").Return(&model.Batch{BatchID: "b1", Status: model.BatchCompletedWithErrors, SucceededRows: 1, FailedRows: 1}, nil)
+
+	err := s.ProcessBatch(context.Background(), "b1")
+
+	assert.NoError(t, err)
+	batchRepo.AssertCalled(t, "UpdateRowStatus", "b1", 2, model.BatchRowPending, model.BatchRowFailed, "db down")
+	ledgerRepo.AssertNumberOfCalls(t, "CreateEntries", 1)
+}
+
+func TestProcessBatch_FailsRowsOverLimitsOrInactive(t *testing.T) {
+	batchRepo, userRepo, ledgerRepo, s := setupBatchService()
+	rows := []model.BatchRow{
+		{BatchID: "b1", RowNumber: 1, UserID: 1, Amount: 100, Status: model.BatchRowPending},
+		{BatchID: "b1", RowNumber: 2, UserID: 2, Amount: 200, Status: model.BatchRowPending},
+	}
+	batchRepo.On("ListPendingRows", "b1", mock.Anything).Return(rows, nil).Once()
+	batchRepo.On("ListPendingRows", "b1", mock.Anything).Return([]model.BatchRow{}, nil)
+	batchRepo.On("UpdateRowStatus", "b1", mock.Anything, model.BatchRowPending, model.BatchRowCredited, "").Return(nil)
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletFrozen}, nil)
+	userRepo.On("GetUserByIDForUpdate", uint(2)).Return(&model.User{UserID: 2, Balance: 4900, KYCTier: model.KYCBasic}, nil)
+	batchRepo.On("UpdateRowStatus", "b1", mock.Anything, model.BatchRowPending, model.BatchRowFailed, mock.Anything).Return(nil)
+	batchRepo.On("RefreshBatchProgress", "b1").Return(&model.Batch{BatchID: "b1", Status: model.BatchCompletedWithErrors, FailedRows: 2}, nil)
+
+	err := s.ProcessBatch(context.Background(), "b1")
+
+	assert.NoError(t, err)
+	batchRepo.AssertCalled(t, "UpdateRowStatus", "b1", 1, model.BatchRowPending, model.BatchRowFailed, model.ErrWalletFrozen.Error())
+	batchRepo.AssertCalled(t, "UpdateRowStatus", "b1", 2, model.BatchRowPending, model.BatchRowFailed, "top-up would exceed kyc tier balance limit")
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+	ledgerRepo.AssertNotCalled(t, "CreateEntries", mock.Anything)
+}
+
+func TestProcessBatch_SkipsAlreadyCreditedRow(t *testing.T) {
+	batchRepo, userRepo, _, s := setupBatchService()
+	batchRepo.On("ListPendingRows", "b1", mock.Anything).Return([]model.BatchRow{{BatchID: "b1", RowNumber: 1, UserID: 1, Amount: 100}}, nil).Once()
+	batchRepo.On("ListPendingRows", "b1", mock.Anything).Return([]model.BatchRow{}, nil)
+	batchRepo.On("UpdateRowStatus", "b1", 1, model.BatchRowPending, model.BatchRowCredited, "").Return(model.ErrStatusChanged)
+	batchRepo.On("RefreshBatchProgress", "b1").Return(&model.Batch{BatchID: "b1", Status: model.BatchCompleted}, nil)
+
+	err := s.ProcessBatch(context.Background(), "b1")
+
+	assert.NoError(t, err)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxHoldTTL           = 30 * 24 * time.Hour
+	expiredHoldBatchSize = 100
+)
+
+type HoldService struct {
+	txManager model.TxManager
+	holdRepo  model.HoldRepository
+	userRepo  model.UserRepository
+	logger    logs.Logger
+}
+
+func NewHoldService(
+	txManager model.TxManager,
+	holdRepo model.HoldRepository,
+	userRepo model.UserRepository,
+	logger logs.Logger,
+) model.HoldService {
+	return &HoldService{
+		txManager: txManager,
+		holdRepo:  holdRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+	}
+}
+
+func (s *HoldService) PlaceHold(ctx context.Context, userID uint, amount float64, reason string, ttl time.Duration) (*model.Hold, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "place hold"); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if ttl <= 0 || ttl > MaxHoldTTL {
+		return nil, model.Invalid("hold expiry is out of range")
+	}
+	if reason == model.HoldReasonWithdrawal || reason == model.HoldReasonPayment {
+		return nil, model.Invalid("hold reason is reserved")
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	var hold *model.Hold
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		var err error
+		hold, err = placeHold(repos, userID, nil, amount, reason, &expiresAt)
+		return err
+	})
+	if err != nil {
+		s.logger.Warnf("place hold for user_id=%d failed: %v", userID, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("hold placed: %s", hold.HoldID)
+	return hold, nil
+}
+
+func (s *HoldService) CaptureHold(ctx context.Context, holdID string) (*model.Hold, error) {
+	hold, err := s.manualHold(ctx, holdID, "capture hold")
+	if err != nil {
+		return nil, err
+	}
+	if hold.ForPayment() {
+		s.logger.Warnf("capture hold refused: hold %s is a payment authorization", holdID)
+		return nil, model.Conflict("hold is a payment authorization")
+	}
+
+	err = s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		user, err := repos.Users.GetUserByIDForUpdate(hold.UserID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		if err := user.CheckActive(); err != nil {
+			return err
+		}
+		return captureHold(repos, hold, "hold_capture", hold.HoldID)
+	})
+	if err != nil {
+		s.logger.Warnf("capture hold %s failed: %v", holdID, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("hold captured: %s", holdID)
+	return hold, nil
+}
+
+func (s *HoldService) ReleaseHold(ctx context.Context, holdID string) (*model.Hold, error) {
+	hold, err := s.manualHold(ctx, holdID, "release hold")
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != model.HoldActive {
+		return nil, model.Conflict("hold is not active")
+	}
+
+	if err := s.holdRepo.UpdateHoldStatus(holdID, model.HoldActive, model.HoldReleased); err != nil {
+		s.logger.Warnf("release hold %s failed: %v", holdID, err)
+		return nil, storeError(err)
+	}
+
+	hold.Status = model.HoldReleased
+	s.logger.Infof("hold released: %s", holdID)
+	return hold, nil
+}
+
+// manualHold loads a hold that the caller wants to capture or release by
+// hand. Holds placed by a withdrawal are settled only through the withdrawal.
+func (s *HoldService) manualHold(ctx context.Context, holdID, action string) (*model.Hold, error) {
+	hold, err := s.holdRepo.GetHoldByID(holdID)
+	if err != nil {
+		return nil, lookupError(err, "hold")
+	}
+	if _, err := authorizeWallet(ctx, s.logger, hold.UserID, action); err != nil {
+		return nil, err
+	}
+	if hold.ForWithdrawal() {
+		s.logger.Warnf("%s refused: hold %s belongs to a withdrawal", action, holdID)
+		return nil, model.Conflict("hold belongs to a withdrawal")
+	}
+	return hold, nil
+}
+
+func (s *HoldService) GetBalance(ctx context.Context, userID uint) (*model.Balance, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "read balance"); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, lookupError(err, "user")
+	}
+	holds, err := s.holdRepo.ListActiveHolds(userID)
+	if err != nil {
+		s.logger.Error("list holds error:", err)
+		return nil, storeError(err)
+	}
+
+	var held float64
+	for _, h := range holds {
+		held += h.Amount
+	}
+	return &model.Balance{
+		UserID:    userID,
+		Balance:   user.Balance,
+		Held:      held,
+		Available: user.Balance - held,
+		Holds:     holds,
+	}, nil
+}
+
+// ReleaseExpiredHolds marks holds whose expiry has passed as expired so the
+// reserved funds become available again. It returns how many were released.
+func (s *HoldService) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	holds, err := s.holdRepo.ListExpiredHolds(time.Now(), expiredHoldBatchSize)
+	if err != nil {
+		s.logger.Error("list expired holds error:", err)
+		return 0, err
+	}
+
+	released := 0
+	for _, h := range holds {
+		if err := s.holdRepo.UpdateHoldStatus(h.HoldID, model.HoldActive, model.HoldExpired); err != nil {
+			if !errors.Is(err, model.ErrStatusChanged) {
+				s.logger.Error("expire hold error:", err)
+			}
+			continue
+		}
+		released++
+	}
+	if released > 0 {
+		s.logger.Infof("released %d expired holds", released)
+	}
+	return released, nil
+}
+
+// placeHold reserves amount on the user's wallet inside an open transaction,
+// for merchantID when the hold is a payment authorization. The user row is
+// locked so concurrent holds and debits see a consistent available balance.
+func placeHold(repos model.TxRepositories, userID uint, merchantID *uint, amount float64, reason string, expiresAt *time.Time) (*model.Hold, error) {
+	user, err := repos.Users.GetUserByIDForUpdate(userID)
+	if err != nil {
+		return nil, lookupError(err, "user")
+	}
+	if err := user.CheckActive(); err != nil {
+		return nil, err
+	}
+	held, err := repos.Holds.SumActiveHolds(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Balance-held < amount {
+		return nil, model.ErrInsufficientFunds
+	}
+
+	now := time.Now()
+	hold := &model.Hold{
+		HoldID:     uuid.New().String(),
+		UserID:     userID,
+		MerchantID: merchantID,
+		Amount:     amount,
+		Reason:     reason,
+		Status:     model.HoldActive,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := repos.Holds.CreateHold(hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// captureHold spends the held amount: the hold is closed and the wallet is
+// debited, with a ledger entry of entryType pointing at reference.
+func captureHold(repos model.TxRepositories, hold *model.Hold, entryType, reference string) error {
+	if hold.Status != model.HoldActive {
+		return model.Conflict("hold is not active")
+	}
+	if hold.ExpiresAt != nil && time.Now().After(*hold.ExpiresAt) {
+		return model.Expired("hold has expired")
+	}
+
+	if err := repos.Holds.UpdateHoldStatus(hold.HoldID, model.HoldActive, model.HoldCaptured); err != nil {
+		return err
+	}
+	if err := repos.Users.DebitUserBalance(hold.UserID, hold.Amount); err != nil {
+		return err
+	}
+	hold.Status = model.HoldCaptured
+	return repos.Ledger.CreateEntries(&model.LedgerEntry{
+		EntryID:   uuid.New().String(),
+		UserID:    hold.UserID,
+		Amount:    -hold.Amount,
+		Type:      entryType,
+		Reference: reference,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupHoldService() (*mocks.UserRepoMock, *mocks.HoldRepoMock, *mocks.LedgerRepoMock, model.HoldService) {
+	userRepo := new(mocks.UserRepoMock)
+	holdRepo := new(mocks.HoldRepoMock)
+	ledgerRepo := new(mocks.LedgerRepoMock)
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:  userRepo,
+		Ledger: ledgerRepo,
+		Holds:  holdRepo,
+	}}
+	return userRepo, holdRepo, ledgerRepo, service.NewHoldService(txManager, holdRepo, userRepo, setupLogger())
+}
+
+func TestPlaceHold_Success(t *testing.T) {
+	userRepo, holdRepo, _, s := setupHoldService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	holdRepo.On("SumActiveHolds", uint(1)).Return(300.0, nil)
+	holdRepo.On("CreateHold", mock.Anything).Return(nil)
+
+	hold, err := s.PlaceHold(systemCtx(), 1, 200.0, "preauth", time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.HoldActive, hold.Status)
+	assert.NotNil(t, hold.ExpiresAt)
+}
+
+func TestPlaceHold_ExceedsAvailableBalance(t *testing.T) {
+	userRepo, holdRepo, _, s := setupHoldService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	holdRepo.On("SumActiveHolds", uint(1)).Return(300.0, nil)
+
+	_, err := s.PlaceHold(systemCtx(), 1, 200.01, "preauth", time.Hour)
+
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	holdRepo.AssertNotCalled(t, "CreateHold", mock.Anything)
+}
+
+func TestCaptureHold_DebitsWallet(t *testing.T) {
+	userRepo, holdRepo, ledgerRepo, s := setupHoldService()
+	expiresAt := time.Now().Add(time.Hour)
+	holdRepo.On("GetHoldByID", "h1").Return(&model.Hold{HoldID: "h1", UserID: 1, Amount: 200, Status: model.HoldActive, ExpiresAt: &expiresAt}, nil)
+	holdRepo.On("UpdateHoldStatus", "h1", model.HoldActive, model.HoldCaptured).Return(nil)
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	userRepo.On("DebitUserBalance", uint(1), 200.0).Return(nil)
+	ledgerRepo.On("CreateEntries", mock.Anything).Return(nil)
+
+	hold, err := s.CaptureHold(systemCtx(), "h1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.HoldCaptured, hold.Status)
+	userRepo.AssertCalled(t, "DebitUserBalance", uint(1), 200.0)
+}
+
+func TestCaptureHold_Expired(t *testing.T) {
+	userRepo, holdRepo, _, s := setupHoldService()
+	expiresAt := time.Now().Add(-time.Minute)
+	holdRepo.On("GetHoldByID", "h1").Return(&model.Hold{HoldID: "h1", UserID: 1, Amount: 200, Status: model.HoldActive, ExpiresAt: &expiresAt}, nil)
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+
+	_, err := s.CaptureHold(systemCtx(), "h1")
+
+	assert.EqualError(t, err, "hold has expired")
+}
+
+func TestCaptureHold_OtherCustomerForbidden(t *testing.T) {
+	userRepo, holdRepo, _, s := setupHoldService()
+	holdRepo.On("GetHoldByID", "h1").Return(&model.Hold{HoldID: "h1", UserID: 1, Amount: 200, Status: model.HoldActive}, nil)
+	other := uint(2)
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "bob", Roles: []string{model.RoleCustomer}, UserID: &other,
+	})
+
+	_, err := s.CaptureHold(ctx, "h1")
+
+	assert.ErrorIs(t, err, model.ErrForbidden)
+	userRepo.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestReleaseHold_WithdrawalHoldRefused(t *testing.T) {
+	_, holdRepo, _, s := setupHoldService()
+	holdRepo.On("GetHoldByID", "h1").Return(&model.Hold{HoldID: "h1", UserID: 1, Amount: 200, Reason: model.HoldReasonWithdrawal, Status: model.HoldActive}, nil)
+
+	_, err := s.ReleaseHold(systemCtx(), "h1")
+
+	assert.Equal(t, model.KindConflict, model.KindOf(err))
+	holdRepo.AssertNotCalled(t, "UpdateHoldStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBalance_SubtractsActiveHolds(t *testing.T) {
+	userRepo, holdRepo, _, s := setupHoldService()
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 1000}, nil)
+	holdRepo.On("ListActiveHolds", uint(1)).Return([]model.Hold{{Amount: 150}, {Amount: 50}}, nil)
+
+	balance, err := s.GetBalance(systemCtx(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, balance.Balance)
+	assert.Equal(t, 200.0, balance.Held)
+	assert.Equal(t, 800.0, balance.Available)
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	_, holdRepo, _, s := setupHoldService()
+	holdRepo.On("ListExpiredHolds", mock.Anything, mock.Anything).Return([]model.Hold{{HoldID: "h1"}, {HoldID: "h2"}}, nil)
+	holdRepo.On("UpdateHoldStatus", "h1", model.HoldActive, model.HoldExpired).Return(nil)
+	holdRepo.On("UpdateHoldStatus", "h2", model.HoldActive, model.HoldExpired).Return(model.ErrStatusChanged)
+
+	released, err := s.ReleaseExpiredHolds(systemCtx())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+}
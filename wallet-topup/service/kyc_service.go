@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+)
+
+type KYCService struct {
+	txManager model.TxManager
+	userRepo  model.UserRepository
+	auditRepo model.AuditRepository
+	logger    logs.Logger
+}
+
+func NewKYCService(
+	txManager model.TxManager,
+	userRepo model.UserRepository,
+	auditRepo model.AuditRepository,
+	logger logs.Logger,
+) model.KYCService {
+	return &KYCService{
+		txManager: txManager,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+// ChangeTier moves a user to another KYC tier and records who did it and why.
+// A downgrade does not touch the existing balance; it only tightens the limits
+// for what comes next.
+func (s *KYCService) ChangeTier(ctx context.Context, actor string, userID uint, tier, reason string) (*model.User, error) {
+	if actor == "" {
+		return nil, model.Invalid("actor identity is required")
+	}
+	if _, ok := model.KYCTiers[tier]; !ok {
+		return nil, model.Invalid("tier must be basic, verified or premium")
+	}
+	if reason == "" {
+		return nil, model.Invalid("reason is required")
+	}
+
+	var user *model.User
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		var err error
+		user, err = repos.Users.GetUserByIDForUpdate(userID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		from := model.TierFor(user.KYCTier).Name
+		if from == tier {
+			return model.Conflict("user is already on kyc tier " + tier)
+		}
+
+		if err := repos.Users.UpdateUserTier(userID, tier); err != nil {
+			return err
+		}
+		user.KYCTier = tier
+		return repos.Audit.CreateAuditLog(newAuditLog(actor, "kyc.tier_changed", "kyc", strconv.FormatUint(uint64(userID), 10), map[string]interface{}{
+			"from":   from,
+			"to":     tier,
+			"reason": reason,
+		}))
+	})
+	if err != nil {
+		s.logger.Warnf("change kyc tier of user_id=%d to %s by %s failed: %v", userID, tier, actor, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("kyc tier changed: user_id=%d tier=%s by %s", userID, tier, actor)
+	return user, nil
+}
+
+func (s *KYCService) GetKYC(ctx context.Context, userID uint) (*model.User, []model.AuditLog, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, lookupError(err, "user")
+	}
+	history, err := s.auditRepo.ListAuditLogs("kyc", strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		s.logger.Error("list audit logs error:", err)
+		return nil, nil, storeError(err)
+	}
+	return user, history, nil
+}
@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupKYCService() (*mocks.UserRepoMock, *mocks.AuditRepoMock, model.KYCService) {
+	userRepo := new(mocks.UserRepoMock)
+	auditRepo := new(mocks.AuditRepoMock)
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users: userRepo,
+		Audit: auditRepo,
+	}}
+	return userRepo, auditRepo, service.NewKYCService(txManager, userRepo, auditRepo, setupLogger())
+}
+
+func TestChangeTier_Upgrade(t *testing.T) {
+	userRepo, auditRepo, s := setupKYCService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1}, nil)
+	userRepo.On("UpdateUserTier", uint(1), model.KYCVerified).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(e *model.AuditLog) bool {
+		return e.Actor == "alice" && e.Action == "kyc.tier_changed" && e.EntityID == "1" &&
+			e.Details == `{"from":"basic","reason":"id card checked","to":"verified"}`
+	})).Return(nil)
+
+	user, err := s.ChangeTier(context.Background(), "alice", 1, model.KYCVerified, "id card checked")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.KYCVerified, user.KYCTier)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestChangeTier_UnknownTier(t *testing.T) {
+	userRepo, _, s := setupKYCService()
+
+	_, err := s.ChangeTier(context.Background(), "alice", 1, "gold", "vip")
+
+	assert.EqualError(t, err, "tier must be basic, verified or premium")
+	userRepo.AssertNotCalled(t, "UpdateUserTier", mock.Anything, mock.Anything)
+}
+
+func TestChangeTier_SameTier(t *testing.T) {
+	userRepo, _, s := setupKYCService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, KYCTier: model.KYCPremium}, nil)
+
+	_, err := s.ChangeTier(context.Background(), "alice", 1, model.KYCPremium, "again")
+
+	assert.EqualError(t, err, "user is already on kyc tier premium")
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"errors"
+	"wallet-topup/model"
+
+	"gorm.io/gorm"
+)
+
+// lookupError reports a failed load of what. Only a missing row is NotFound;
+// anything else means the store could not be reached and is Unavailable, so
+// an outage is not mistaken for a bad ID.
+func lookupError(err error, what string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.NotFound(what + " not found")
+	}
+	return model.Unavailable("unable to load "+what, err)
+}
+
+// storeError marks a failed read or write of the database or Redis as
+// Unavailable. Errors that already carry a kind pass through unchanged.
+func storeError(err error) error {
+	if model.KindOf(err) != "" {
+		return err
+	}
+	return model.Unavailable("storage temporarily unavailable", err)
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupOAuthService() (*mocks.OAuthClientRepoMock, *mocks.TokenIssuerMock, model.OAuthService) {
+	repo := new(mocks.OAuthClientRepoMock)
+	issuer := new(mocks.TokenIssuerMock)
+	return repo, issuer, service.NewOAuthService(repo, issuer, setupLogger())
+}
+
+func oauthClient(secret string) *model.OAuthClient {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.MinCost)
+	return &model.OAuthClient{ClientID: "payouts", SecretHash: string(hash), Scopes: "txn:read refund:*"}
+}
+
+func TestOAuthIssueToken_DefaultsToAllowedScopes(t *testing.T) {
+	repo, issuer, s := setupOAuthService()
+	repo.On("GetClient", "payouts").Return(oauthClient("s3cret"), nil)
+	issuer.On("IssueClientToken", "payouts", []string{"txn:read", "refund:*"}).Return(accessToken("client"), nil)
+
+	token, scopes, err := s.IssueToken(context.Background(), "payouts", "s3cret", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "client", token.Token)
+	assert.Equal(t, []string{"txn:read", "refund:*"}, scopes)
+}
+
+func TestOAuthIssueToken_NarrowsToRequestedScopes(t *testing.T) {
+	repo, issuer, s := setupOAuthService()
+	repo.On("GetClient", "payouts").Return(oauthClient("s3cret"), nil)
+	issuer.On("IssueClientToken", "payouts", []string{"refund:create"}).Return(accessToken("client"), nil)
+
+	_, scopes, err := s.IssueToken(context.Background(), "payouts", "s3cret", []string{"refund:create"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"refund:create"}, scopes)
+}
+
+func TestOAuthIssueToken_RejectsScopeOutsideClient(t *testing.T) {
+	repo, issuer, s := setupOAuthService()
+	repo.On("GetClient", "payouts").Return(oauthClient("s3cret"), nil)
+
+	_, _, err := s.IssueToken(context.Background(), "payouts", "s3cret", []string{"admin:adjust"})
+
+	assert.ErrorIs(t, err, model.ErrInvalidScope)
+	issuer.AssertNotCalled(t, "IssueClientToken", mock.Anything, mock.Anything)
+}
+
+func TestOAuthIssueToken_BadCredentials(t *testing.T) {
+	repo, issuer, s := setupOAuthService()
+	disabled := oauthClient("s3cret")
+	disabled.ClientID, disabled.Disabled = "old", true
+	repo.On("GetClient", "payouts").Return(oauthClient("s3cret"), nil)
+	repo.On("GetClient", "old").Return(disabled, nil)
+	repo.On("GetClient", "nobody").Return((*model.OAuthClient)(nil), errors.New("not found"))
+
+	for _, tc := range [][2]string{{"payouts", "wrong"}, {"old", "s3cret"}, {"nobody", "s3cret"}} {
+		_, _, err := s.IssueToken(context.Background(), tc[0], tc[1], nil)
+		assert.ErrorIs(t, err, model.ErrInvalidClient, tc[0])
+	}
+	issuer.AssertNotCalled(t, "IssueClientToken", mock.Anything, mock.Anything)
+}
+
+func TestOAuthCreateClient_ValidatesScopes(t *testing.T) {
+	repo, _, s := setupOAuthService()
+
+	_, _, err := s.CreateClient(context.Background(), "admin", "payouts", "Payouts", []string{"refund"})
+
+	assert.EqualError(t, err, "invalid scope: refund")
+	repo.AssertNotCalled(t, "CreateClient", mock.Anything)
+}
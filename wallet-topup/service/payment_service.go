@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxPaymentAmount = 100000.00
+	// MaxPaymentAuthorizationTTL caps how long a customer's payment
+	// authorization can reserve funds for a merchant.
+	MaxPaymentAuthorizationTTL = 7 * 24 * time.Hour
+)
+
+type PaymentService struct {
+	txManager    model.TxManager
+	paymentRepo  model.PaymentRepository
+	merchantRepo model.MerchantRepository
+	logger       logs.Logger
+}
+
+func NewPaymentService(
+	txManager model.TxManager,
+	paymentRepo model.PaymentRepository,
+	merchantRepo model.MerchantRepository,
+	logger logs.Logger,
+) model.PaymentService {
+	return &PaymentService{
+		txManager:    txManager,
+		paymentRepo:  paymentRepo,
+		merchantRepo: merchantRepo,
+		logger:       logger,
+	}
+}
+
+// AuthorizePayment lets the customer reserve amount for merchantID. The
+// merchant's API key can then capture it with CreatePayment, which is the only
+// way a merchant can debit a wallet.
+func (s *PaymentService) AuthorizePayment(ctx context.Context, merchantID, userID uint, amount float64, ttl time.Duration) (*model.Hold, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "authorize payment"); err != nil {
+		return nil, err
+	}
+	if err := s.checkMerchant(merchantID); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if amount > MaxPaymentAmount {
+		return nil, model.LimitExceeded("amount exceeds maximum allowed")
+	}
+	if ttl <= 0 || ttl > MaxPaymentAuthorizationTTL {
+		return nil, model.Invalid("authorization expiry is out of range")
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	var hold *model.Hold
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		var err error
+		hold, err = placeHold(repos, userID, &merchantID, amount, model.HoldReasonPayment, &expiresAt)
+		return err
+	})
+	if err != nil {
+		s.logger.Warnf("payment authorization for user_id=%d merchant_id=%d failed: %v", userID, merchantID, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("payment authorized: %s", hold.HoldID)
+	return hold, nil
+}
+
+// CreatePayment debits the user's wallet for a merchant order. Repeating a
+// request with the same merchant and order reference returns the original
+// payment instead of charging again. With authorizationID the payment
+// captures that authorization, which must be the customer's for this
+// merchant and cover amount; this is how merchant API keys take payments.
+// Without it the caller must be allowed to act on the wallet itself.
+func (s *PaymentService) CreatePayment(ctx context.Context, merchantID, userID uint, amount float64, orderReference, description, authorizationID string) (*model.Payment, error) {
+	if err := s.authorizePayment(ctx, merchantID, userID, authorizationID); err != nil {
+		return nil, err
+	}
+	if err := s.checkMerchant(merchantID); err != nil {
+		return nil, err
+	}
+	if orderReference == "" {
+		return nil, model.Invalid("order reference is required")
+	}
+	if amount <= 0 {
+		s.logger.Warnf("invalid payment amount %.2f for user_id=%d", amount, userID)
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if amount > MaxPaymentAmount {
+		s.logger.Warnf("payment amount %.2f exceeds limit for user_id=%d", amount, userID)
+		return nil, model.LimitExceeded("amount exceeds maximum allowed")
+	}
+
+	if existing, err := s.paymentRepo.GetPaymentByReference(merchantID, orderReference); err == nil {
+		return s.replay(existing, userID, amount)
+	}
+
+	now := time.Now()
+	payment := &model.Payment{
+		PaymentID:      uuid.New().String(),
+		MerchantID:     merchantID,
+		UserID:         userID,
+		Amount:         amount,
+		OrderReference: orderReference,
+		Description:    description,
+		Status:         model.PaymentCompleted,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		user, err := repos.Users.GetUserByIDForUpdate(userID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		if err := user.CheckActive(); err != nil {
+			return err
+		}
+		if authorizationID != "" {
+			if err := captureAuthorization(repos, authorizationID, merchantID, userID, amount); err != nil {
+				return err
+			}
+		}
+		if err := repos.Users.DebitUserBalance(userID, amount); err != nil {
+			return err
+		}
+		if err := repos.Payments.CreatePayment(payment); err != nil {
+			return err
+		}
+		return repos.Ledger.CreateEntries(&model.LedgerEntry{
+			EntryID:   uuid.New().String(),
+			UserID:    userID,
+			Amount:    -amount,
+			Type:      "payment",
+			Reference: payment.PaymentID,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		// A concurrent request with the same reference may have won the race
+		// on the unique index; treat it as a replay.
+		if existing, lookupErr := s.paymentRepo.GetPaymentByReference(merchantID, orderReference); lookupErr == nil {
+			return s.replay(existing, userID, amount)
+		}
+		s.logger.Warnf("payment for user_id=%d merchant_id=%d failed: %v", userID, merchantID, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("payment completed: %s", payment.PaymentID)
+	return payment, nil
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, merchantID uint, paymentID string) (*model.Payment, error) {
+	if err := authorizeMerchant(ctx, s.logger, merchantID, "get payment"); err != nil {
+		return nil, err
+	}
+	payment, err := s.paymentRepo.GetPaymentByID(paymentID)
+	if err != nil {
+		return nil, lookupError(err, "payment")
+	}
+	if payment.MerchantID != merchantID {
+		return nil, model.NotFound("payment not found")
+	}
+	return payment, nil
+}
+
+// RefundPayment returns amount of a payment to the wallet. Several partial
+// refunds are allowed as long as their total does not exceed the payment.
+// Frozen and suspended wallets can still be refunded; closed ones must stay
+// at zero, and the refund may not take the wallet over its KYC tier's
+// balance limit.
+func (s *PaymentService) RefundPayment(ctx context.Context, merchantID uint, paymentID string, amount float64) (*model.Payment, error) {
+	if err := authorizeMerchant(ctx, s.logger, merchantID, "refund"); err != nil {
+		return nil, err
+	}
+	if err := s.checkMerchant(merchantID); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+
+	var payment *model.Payment
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		var err error
+		payment, err = repos.Payments.GetPaymentByIDForUpdate(paymentID)
+		if err != nil {
+			return lookupError(err, "payment")
+		}
+		if payment.MerchantID != merchantID {
+			return model.NotFound("payment not found")
+		}
+
+		refunded := math.Round((payment.RefundedAmount+amount)*100) / 100
+		if refunded > payment.Amount {
+			return model.LimitExceeded("refund exceeds payment amount")
+		}
+		status := model.PaymentPartiallyRefunded
+		if refunded == payment.Amount {
+			status = model.PaymentRefunded
+		}
+
+		user, err := repos.Users.GetUserByIDForUpdate(payment.UserID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		if user.Status == model.WalletClosed {
+			return model.ErrWalletClosed
+		}
+		tier := model.TierFor(user.KYCTier)
+		if user.Balance+amount > tier.MaxBalance {
+			return model.LimitExceeded("refund would exceed kyc tier balance limit").
+				WithDetails(map[string]any{"tier": tier.Name, "limit": tier.MaxBalance})
+		}
+
+		if err := repos.Payments.UpdatePaymentRefund(paymentID, refunded, status); err != nil {
+			return err
+		}
+		if err := repos.Users.UpdateUserBalance(payment.UserID, amount); err != nil {
+			return err
+		}
+		payment.RefundedAmount = refunded
+		payment.Status = status
+		payment.UpdatedAt = time.Now()
+		return repos.Ledger.CreateEntries(&model.LedgerEntry{
+			EntryID:   uuid.New().String(),
+			UserID:    payment.UserID,
+			Amount:    amount,
+			Type:      "payment_refund",
+			Reference: paymentID,
+			CreatedAt: payment.UpdatedAt,
+		})
+	})
+	if err != nil {
+		s.logger.Warnf("refund of payment %s failed: %v", paymentID, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("payment refunded: %s (%.2f)", paymentID, amount)
+	return payment, nil
+}
+
+// authorizePayment checks who may take a payment. Capturing an authorization
+// is up to the merchant it was granted to; paying without one needs access
+// to the wallet, which merchant keys never have.
+func (s *PaymentService) authorizePayment(ctx context.Context, merchantID, userID uint, authorizationID string) error {
+	if authorizationID != "" {
+		return authorizeMerchant(ctx, s.logger, merchantID, "capture payment authorization")
+	}
+	if p, ok := model.PrincipalFrom(ctx); ok && p.IsMerchant() {
+		s.logger.Warnf("pay denied: %s sent no authorization for user_id=%d", p.Subject, userID)
+		return model.ErrPaymentAuthorizationRequired
+	}
+	_, err := authorizeWallet(ctx, s.logger, userID, "pay")
+	return err
+}
+
+// captureAuthorization closes the payment authorization holdID so its funds
+// can be debited for a payment of amount. The rest of an authorization that
+// is only partly used is released with it.
+func captureAuthorization(repos model.TxRepositories, holdID string, merchantID, userID uint, amount float64) error {
+	hold, err := repos.Holds.GetHoldByID(holdID)
+	if err != nil {
+		return lookupError(err, "payment authorization")
+	}
+	if !hold.ForPayment() || hold.UserID != userID || hold.MerchantID == nil || *hold.MerchantID != merchantID {
+		return model.NotFound("payment authorization not found")
+	}
+	if hold.Status != model.HoldActive {
+		return model.Conflict("payment authorization is " + hold.Status)
+	}
+	if hold.ExpiresAt != nil && time.Now().After(*hold.ExpiresAt) {
+		return model.Expired("payment authorization has expired")
+	}
+	if amount > hold.Amount {
+		return model.LimitExceeded("amount exceeds the authorized amount").
+			WithDetails(map[string]any{"authorized": hold.Amount})
+	}
+	return repos.Holds.UpdateHoldStatus(holdID, model.HoldActive, model.HoldCaptured)
+}
+
+func (s *PaymentService) checkMerchant(merchantID uint) error {
+	merchant, err := s.merchantRepo.GetMerchantByID(merchantID)
+	if err != nil {
+		s.logger.Error("load merchant error:", err)
+		return lookupError(err, "merchant")
+	}
+	if merchant.Status != "active" {
+		s.logger.Warnf("merchant_id=%d is %s", merchantID, merchant.Status)
+		return model.Conflict("merchant is not active")
+	}
+	return nil
+}
+
+func (s *PaymentService) replay(existing *model.Payment, userID uint, amount float64) (*model.Payment, error) {
+	if existing.UserID != userID || existing.Amount != amount {
+		return nil, model.Conflict("order reference already used for a different payment")
+	}
+	s.logger.Infof("payment replayed: %s", existing.PaymentID)
+	return existing, nil
+}
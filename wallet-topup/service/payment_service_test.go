@@ -0,0 +1,259 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type paymentMocks struct {
+	users     *mocks.UserRepoMock
+	payments  *mocks.PaymentRepoMock
+	merchants *mocks.MerchantRepoMock
+	holds     *mocks.HoldRepoMock
+	ledger    *mocks.LedgerRepoMock
+}
+
+func setupPaymentService() (*paymentMocks, model.PaymentService) {
+	m := &paymentMocks{
+		users:     new(mocks.UserRepoMock),
+		payments:  new(mocks.PaymentRepoMock),
+		merchants: new(mocks.MerchantRepoMock),
+		holds:     new(mocks.HoldRepoMock),
+		ledger:    new(mocks.LedgerRepoMock),
+	}
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:    m.users,
+		Ledger:   m.ledger,
+		Payments: m.payments,
+		Holds:    m.holds,
+	}}
+	m.merchants.On("GetMerchantByID", uint(7)).Return(&model.Merchant{MerchantID: 7, Status: "active"}, nil)
+	return m, service.NewPaymentService(txManager, m.payments, m.merchants, setupLogger())
+}
+
+func TestCreatePayment_Success(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByReference", uint(7), "order-1").Return((*model.Payment)(nil), errors.New("not found"))
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	m.users.On("DebitUserBalance", uint(1), 120.0).Return(nil)
+	m.payments.On("CreatePayment", mock.Anything).Return(nil)
+	m.ledger.On("CreateEntries", mock.Anything).Return(nil)
+
+	p, err := s.CreatePayment(systemCtx(), 7, 1, 120.0, "order-1", "coffee", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PaymentCompleted, p.Status)
+	assert.Equal(t, "order-1", p.OrderReference)
+}
+
+func TestCreatePayment_Idempotent(t *testing.T) {
+	m, s := setupPaymentService()
+	existing := &model.Payment{PaymentID: "p1", MerchantID: 7, UserID: 1, Amount: 120, OrderReference: "order-1", Status: model.PaymentCompleted}
+	m.payments.On("GetPaymentByReference", uint(7), "order-1").Return(existing, nil)
+
+	p, err := s.CreatePayment(systemCtx(), 7, 1, 120.0, "order-1", "coffee", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", p.PaymentID)
+	m.users.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+
+	_, err = s.CreatePayment(systemCtx(), 7, 1, 99.0, "order-1", "coffee", "")
+	assert.EqualError(t, err, "order reference already used for a different payment")
+}
+
+func TestCreatePayment_InsufficientFunds(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByReference", uint(7), "order-1").Return((*model.Payment)(nil), errors.New("not found"))
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1}, nil)
+	m.users.On("DebitUserBalance", uint(1), 120.0).Return(model.ErrInsufficientFunds)
+
+	_, err := s.CreatePayment(systemCtx(), 7, 1, 120.0, "order-1", "", "")
+
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	m.payments.AssertNotCalled(t, "CreatePayment", mock.Anything)
+}
+
+func merchantCtx(merchantID uint) context.Context {
+	return model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "merchant", Roles: []string{model.RoleMerchant}, MerchantID: &merchantID,
+	})
+}
+
+func TestCreatePayment_MerchantNeedsAuthorization(t *testing.T) {
+	m, s := setupPaymentService()
+
+	_, err := s.CreatePayment(merchantCtx(7), 7, 1, 120.0, "order-1", "coffee", "")
+
+	assert.ErrorIs(t, err, model.ErrPaymentAuthorizationRequired)
+	m.users.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
+
+func paymentAuthorization(merchantID uint, amount float64) *model.Hold {
+	expiresAt := time.Now().Add(time.Hour)
+	return &model.Hold{
+		HoldID: "a1", UserID: 1, MerchantID: &merchantID, Amount: amount,
+		Reason: model.HoldReasonPayment, Status: model.HoldActive, ExpiresAt: &expiresAt,
+	}
+}
+
+func TestCreatePayment_MerchantCapturesAuthorization(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByReference", uint(7), "order-1").Return((*model.Payment)(nil), errors.New("not found"))
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	m.holds.On("GetHoldByID", "a1").Return(paymentAuthorization(7, 150), nil)
+	m.holds.On("UpdateHoldStatus", "a1", model.HoldActive, model.HoldCaptured).Return(nil)
+	m.users.On("DebitUserBalance", uint(1), 120.0).Return(nil)
+	m.payments.On("CreatePayment", mock.Anything).Return(nil)
+	m.ledger.On("CreateEntries", mock.Anything).Return(nil)
+
+	p, err := s.CreatePayment(merchantCtx(7), 7, 1, 120.0, "order-1", "coffee", "a1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 120.0, p.Amount)
+	m.holds.AssertExpectations(t)
+}
+
+func TestCreatePayment_AuthorizationForAnotherMerchant(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByReference", uint(7), "order-1").Return((*model.Payment)(nil), errors.New("not found"))
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	m.holds.On("GetHoldByID", "a1").Return(paymentAuthorization(8, 150), nil)
+
+	_, err := s.CreatePayment(merchantCtx(7), 7, 1, 120.0, "order-1", "coffee", "a1")
+
+	assert.EqualError(t, err, "payment authorization not found")
+	m.users.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestCreatePayment_AmountOverAuthorization(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByReference", uint(7), "order-1").Return((*model.Payment)(nil), errors.New("not found"))
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	m.holds.On("GetHoldByID", "a1").Return(paymentAuthorization(7, 100), nil)
+
+	_, err := s.CreatePayment(merchantCtx(7), 7, 1, 120.0, "order-1", "coffee", "a1")
+
+	assert.EqualError(t, err, "amount exceeds the authorized amount")
+	m.holds.AssertNotCalled(t, "UpdateHoldStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthorizePayment_HoldsFundsForMerchant(t *testing.T) {
+	m, s := setupPaymentService()
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500}, nil)
+	m.holds.On("SumActiveHolds", uint(1)).Return(0.0, nil)
+	m.holds.On("CreateHold", mock.Anything).Return(nil)
+
+	hold, err := s.AuthorizePayment(customerCtx(1), 7, 1, 150.0, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.HoldReasonPayment, hold.Reason)
+	assert.Equal(t, uint(7), *hold.MerchantID)
+}
+
+func TestCreatePayment_InactiveMerchant(t *testing.T) {
+	m, s := setupPaymentService()
+	m.merchants.On("GetMerchantByID", uint(8)).Return(&model.Merchant{MerchantID: 8, Status: "disabled"}, nil)
+
+	_, err := s.CreatePayment(systemCtx(), 8, 1, 120.0, "order-1", "", "")
+
+	assert.EqualError(t, err, "merchant is not active")
+}
+
+func TestRefundPayment_PartialThenFull(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByIDForUpdate", "p1").Return(&model.Payment{PaymentID: "p1", MerchantID: 7, UserID: 1, Amount: 100, RefundedAmount: 40}, nil)
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 200, KYCTier: model.KYCVerified}, nil)
+	m.payments.On("UpdatePaymentRefund", "p1", 100.0, model.PaymentRefunded).Return(nil)
+	m.users.On("UpdateUserBalance", uint(1), 60.0).Return(nil)
+	m.ledger.On("CreateEntries", mock.Anything).Return(nil)
+
+	p, err := s.RefundPayment(merchantCtx(7), 7, "p1", 60.0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PaymentRefunded, p.Status)
+	assert.Equal(t, 100.0, p.RefundedAmount)
+}
+
+func TestRefundPayment_ExceedsPayment(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByIDForUpdate", "p1").Return(&model.Payment{PaymentID: "p1", MerchantID: 7, UserID: 1, Amount: 100, RefundedAmount: 40}, nil)
+
+	_, err := s.RefundPayment(systemCtx(), 7, "p1", 60.01)
+
+	assert.EqualError(t, err, "refund exceeds payment amount")
+	m.users.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_ClosedWallet(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByIDForUpdate", "p1").Return(&model.Payment{PaymentID: "p1", MerchantID: 7, UserID: 1, Amount: 100}, nil)
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletClosed}, nil)
+
+	_, err := s.RefundPayment(merchantCtx(7), 7, "p1", 50.0)
+
+	assert.ErrorIs(t, err, model.ErrWalletClosed)
+	m.payments.AssertNotCalled(t, "UpdatePaymentRefund", mock.Anything, mock.Anything, mock.Anything)
+	m.users.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_OverTierBalanceLimit(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByIDForUpdate", "p1").Return(&model.Payment{PaymentID: "p1", MerchantID: 7, UserID: 1, Amount: 100}, nil)
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: model.TierFor(model.KYCBasic).MaxBalance - 10, KYCTier: model.KYCBasic}, nil)
+
+	_, err := s.RefundPayment(merchantCtx(7), 7, "p1", 50.0)
+
+	assert.EqualError(t, err, "refund would exceed kyc tier balance limit")
+	m.users.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_DatabaseDownIsUnavailable(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByIDForUpdate", "p1").Return(&model.Payment{PaymentID: "p1", MerchantID: 7, UserID: 1, Amount: 100}, nil)
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, KYCTier: model.KYCVerified}, nil)
+	m.payments.On("UpdatePaymentRefund", "p1", 50.0, model.PaymentPartiallyRefunded).Return(errors.New("connection reset"))
+
+	_, err := s.RefundPayment(merchantCtx(7), 7, "p1", 50.0)
+
+	assert.Equal(t, model.KindUnavailable, model.KindOf(err))
+}
+
+func TestGetPayment_UnknownIsNotFound(t *testing.T) {
+	m, s := setupPaymentService()
+	m.payments.On("GetPaymentByID", "p1").Return((*model.Payment)(nil), gorm.ErrRecordNotFound)
+
+	_, err := s.GetPayment(merchantCtx(7), 7, "p1")
+
+	assert.Equal(t, model.KindNotFound, model.KindOf(err))
+}
+
+func TestRefundPayment_OtherMerchantForbidden(t *testing.T) {
+	m, s := setupPaymentService()
+
+	_, err := s.RefundPayment(merchantCtx(8), 7, "p1", 10.0)
+
+	assert.ErrorIs(t, err, model.ErrForbidden)
+	m.payments.AssertNotCalled(t, "GetPaymentByIDForUpdate", mock.Anything)
+}
+
+func TestGetPayment_CustomerForbidden(t *testing.T) {
+	m, s := setupPaymentService()
+	owner := uint(1)
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "alice", Roles: []string{model.RoleCustomer}, UserID: &owner,
+	})
+
+	_, err := s.GetPayment(ctx, 7, "p1")
+
+	assert.ErrorIs(t, err, model.ErrForbidden)
+	m.payments.AssertNotCalled(t, "GetPaymentByID", mock.Anything)
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupQuoteService() (*mocks.TransactionRepoMock, *mocks.RedisMock, model.WalletService) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	redisMock := new(mocks.RedisMock)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 1000, KYCTier: model.KYCVerified}, nil)
+	txnRepo.On("SumTopUpsSince", uint(1), mock.Anything).Return(20000.0, nil)
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, setupLogger(), service.WithQuoteSecret([]byte("test-secret")))
+	return txnRepo, redisMock, s
+}
+
+func TestQuoteTopUp_DoesNotPersist(t *testing.T) {
+	txnRepo, redisMock, s := setupQuoteService()
+
+	quote, err := s.QuoteTopUp(systemCtx(), 1, 500.0, "credit_card")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 500.0, quote.Charge())
+	assert.Equal(t, 500.0, quote.Credit())
+	assert.Equal(t, 79500.0, quote.LimitRemaining)
+	assert.NotEmpty(t, quote.Token)
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+	redisMock.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestQuoteTopUp_EnforcesLimits(t *testing.T) {
+	_, _, s := setupQuoteService()
+
+	_, err := s.QuoteTopUp(systemCtx(), 1, 90000.0, "credit_card")
+
+	assert.EqualError(t, err, "amount exceeds kyc tier transaction limit")
+}
+
+func TestVerifyQuote_UsesQuotedTerms(t *testing.T) {
+	txnRepo, redisMock, s := setupQuoteService()
+	quote, _ := s.QuoteTopUp(systemCtx(), 1, 500.0, "promptpay")
+
+	redisMock.On("SetNX", mock.Anything, "quote:"+quote.QuoteID, mock.Anything, mock.Anything).Return(true, nil)
+	redisMock.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	txnRepo.On("CreateTransaction", mock.Anything).Return(nil)
+
+	txn, err := s.VerifyQuote(systemCtx(), quote.Token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), txn.UserID)
+	assert.Equal(t, 500.0, txn.Amount)
+	assert.Equal(t, "promptpay", txn.PaymentMethod)
+	assert.Equal(t, "verified", txn.Status)
+}
+
+func TestVerifyQuote_AlreadyUsed(t *testing.T) {
+	txnRepo, redisMock, s := setupQuoteService()
+	quote, _ := s.QuoteTopUp(systemCtx(), 1, 500.0, "credit_card")
+	redisMock.On("SetNX", mock.Anything, "quote:"+quote.QuoteID, mock.Anything, mock.Anything).Return(false, nil)
+
+	_, err := s.VerifyQuote(systemCtx(), quote.Token)
+
+	assert.EqualError(t, err, "quote already used")
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+}
+
+func TestVerifyQuote_LimitFailureKeepsQuote(t *testing.T) {
+	_, _, s := setupQuoteService()
+	quote, _ := s.QuoteTopUp(systemCtx(), 1, 500.0, "credit_card")
+
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	redisMock := new(mocks.RedisMock)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 1000, KYCTier: model.KYCVerified}, nil)
+	txnRepo.On("SumTopUpsSince", uint(1), mock.Anything).Return(99800.0, nil)
+	later := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, setupLogger(), service.WithQuoteSecret([]byte("test-secret")))
+
+	_, err := later.VerifyQuote(systemCtx(), quote.Token)
+
+	assert.Equal(t, model.KindLimitExceeded, model.KindOf(err))
+	redisMock.AssertNotCalled(t, "SetNX", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerifyQuote_CreateFailureReleasesQuote(t *testing.T) {
+	txnRepo, redisMock, s := setupQuoteService()
+	quote, _ := s.QuoteTopUp(systemCtx(), 1, 500.0, "credit_card")
+
+	redisMock.On("SetNX", mock.Anything, "quote:"+quote.QuoteID, mock.Anything, mock.Anything).Return(true, nil)
+	redisMock.On("Del", mock.Anything, []string{"quote:" + quote.QuoteID}).Return(nil)
+	txnRepo.On("CreateTransaction", mock.Anything).Return(errors.New("connection refused"))
+
+	_, err := s.VerifyQuote(systemCtx(), quote.Token)
+
+	assert.Error(t, err)
+	redisMock.AssertCalled(t, "Del", mock.Anything, []string{"quote:" + quote.QuoteID})
+}
+
+func TestVerifyQuote_TamperedToken(t *testing.T) {
+	_, _, s := setupQuoteService()
+	quote, _ := s.QuoteTopUp(systemCtx(), 1, 500.0, "credit_card")
+
+	other := service.NewWalletService(nil, nil, nil, nil, setupLogger(), service.WithQuoteSecret([]byte("other-secret")))
+	_, err := other.VerifyQuote(systemCtx(), quote.Token)
+	assert.EqualError(t, err, "invalid quote token")
+
+	_, err = s.VerifyQuote(systemCtx(), "e30."+quote.Token[len(quote.Token)-10:])
+	assert.EqualError(t, err, "invalid quote token")
+}
+
+func TestVerifyQuote_MerchantRedeemsCustomerQuote(t *testing.T) {
+	txnRepo, redisMock, s := setupQuoteService()
+	quote, _ := s.QuoteTopUp(customerCtx(1), 1, 500.0, "credit_card")
+
+	redisMock.On("SetNX", mock.Anything, "quote:"+quote.QuoteID, mock.Anything, mock.Anything).Return(true, nil)
+	redisMock.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	txnRepo.On("CreateTransaction", mock.Anything).Return(nil)
+
+	txn, err := s.VerifyQuote(merchantCtx(7), quote.Token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), txn.UserID)
+	assert.Equal(t, 500.0, txn.Amount)
+}
+
+func TestVerifyTransaction_MerchantNeedsQuote(t *testing.T) {
+	txnRepo, _, s := setupQuoteService()
+
+	_, err := s.VerifyTransaction(merchantCtx(7), 1, 500.0, "credit_card")
+
+	assert.ErrorIs(t, err, model.ErrQuoteRequired)
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+	"wallet-topup/model"
+)
+
+// QuoteTTL is how long a quote token can be redeemed at /verify.
+const QuoteTTL = 5 * time.Minute
+
+type quoteClaims struct {
+	QuoteID       string  `json:"qid"`
+	UserID        uint    `json:"uid"`
+	Amount        float64 `json:"amt"`
+	PaymentMethod string  `json:"pm"`
+	Fee           float64 `json:"fee"`
+	Bonus         float64 `json:"bonus"`
+	ExpiresAt     int64   `json:"exp"`
+}
+
+// signQuote encodes the quote terms as base64url(JSON).base64url(HMAC-SHA256).
+func signQuote(secret []byte, q *model.Quote) string {
+	payload, _ := json.Marshal(quoteClaims{
+		QuoteID:       q.QuoteID,
+		UserID:        q.UserID,
+		Amount:        q.Amount,
+		PaymentMethod: q.PaymentMethod,
+		Fee:           q.Fee,
+		Bonus:         q.Bonus,
+		ExpiresAt:     q.ExpiresAt.Unix(),
+	})
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(quoteMAC(secret, body))
+}
+
+func parseQuote(secret []byte, token string) (*model.Quote, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, model.Invalid("invalid quote token")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, quoteMAC(secret, body)) {
+		return nil, model.Invalid("invalid quote token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, model.Invalid("invalid quote token")
+	}
+	var claims quoteClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, model.Invalid("invalid quote token")
+	}
+	return &model.Quote{
+		QuoteID:       claims.QuoteID,
+		UserID:        claims.UserID,
+		Amount:        claims.Amount,
+		PaymentMethod: claims.PaymentMethod,
+		Fee:           claims.Fee,
+		Bonus:         claims.Bonus,
+		ExpiresAt:     time.Unix(claims.ExpiresAt, 0),
+		Token:         token,
+	}, nil
+}
+
+func quoteMAC(secret []byte, body string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte("quote:" + body))
+	return h.Sum(nil)
+}
+
+func randomKey() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"time"
+	"wallet-topup/cron"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultScheduleRetries = 3
+	MaxScheduleRetries     = 5
+
+	dueScheduleBatchSize = 50
+	scheduleLease        = 5 * time.Minute
+	scheduleRetryBackoff = 15 * time.Minute
+	scheduleRunHistory   = 50
+)
+
+type ScheduleService struct {
+	scheduleRepo model.ScheduleRepository
+	wallet       model.WalletService
+	payments     model.PaymentProvider
+	logger       logs.Logger
+}
+
+func NewScheduleService(
+	scheduleRepo model.ScheduleRepository,
+	wallet model.WalletService,
+	payments model.PaymentProvider,
+	logger logs.Logger,
+) model.ScheduleService {
+	return &ScheduleService{
+		scheduleRepo: scheduleRepo,
+		wallet:       wallet,
+		payments:     payments,
+		logger:       logger,
+	}
+}
+
+func (s *ScheduleService) CreateSchedule(ctx context.Context, schedule *model.TopUpSchedule) (*model.TopUpSchedule, error) {
+	if _, err := authorizeWallet(ctx, s.logger, schedule.UserID, "create schedule"); err != nil {
+		return nil, err
+	}
+	if _, err := s.wallet.GetUserByID(schedule.UserID); err != nil {
+		return nil, lookupError(err, "user")
+	}
+	if schedule.Amount <= 0 {
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if schedule.PaymentToken == "" {
+		return nil, model.Invalid("payment token is required")
+	}
+
+	switch schedule.FailurePolicy {
+	case "":
+		schedule.FailurePolicy = model.FailurePolicyRetry
+	case model.FailurePolicyRetry, model.FailurePolicySkip:
+	default:
+		return nil, model.Invalid("failure policy must be retry or skip")
+	}
+	if schedule.FailurePolicy == model.FailurePolicySkip {
+		schedule.MaxRetries = 0
+	} else if schedule.MaxRetries == 0 {
+		schedule.MaxRetries = DefaultScheduleRetries
+	}
+	if schedule.MaxRetries < 0 || schedule.MaxRetries > MaxScheduleRetries {
+		return nil, model.Invalid("max retries is out of range")
+	}
+
+	now := time.Now()
+	next, err := nextRun(schedule.CronExpr, now)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.ScheduleID = uuid.New().String()
+	schedule.Status = model.ScheduleActive
+	schedule.RetryCount = 0
+	schedule.NextRunAt = next
+	schedule.LastRunAt = nil
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	if err := s.scheduleRepo.CreateSchedule(schedule); err != nil {
+		s.logger.Error("failed to create schedule:", err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("schedule created: %s next run %s", schedule.ScheduleID, next.Format(time.RFC3339))
+	return schedule, nil
+}
+
+func (s *ScheduleService) GetSchedule(ctx context.Context, scheduleID string) (*model.TopUpSchedule, error) {
+	schedule, err := s.scheduleRepo.GetScheduleByID(scheduleID)
+	if err != nil {
+		return nil, lookupError(err, "schedule")
+	}
+	if _, err := authorizeWallet(ctx, s.logger, schedule.UserID, "read schedule"); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *ScheduleService) PauseSchedule(ctx context.Context, scheduleID string) (*model.TopUpSchedule, error) {
+	schedule, err := s.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != model.ScheduleActive {
+		return nil, model.Conflict("schedule is not active")
+	}
+
+	if err := s.scheduleRepo.UpdateScheduleStatus(scheduleID, model.ScheduleActive, model.SchedulePaused, schedule.NextRunAt); err != nil {
+		s.logger.Error("pause schedule error:", err)
+		return nil, storeError(err)
+	}
+
+	schedule.Status = model.SchedulePaused
+	schedule.RetryCount = 0
+	s.logger.Infof("schedule paused: %s", scheduleID)
+	return schedule, nil
+}
+
+// ResumeSchedule reactivates a paused schedule from its next occurrence after
+// now; occurrences missed while paused are not made up.
+func (s *ScheduleService) ResumeSchedule(ctx context.Context, scheduleID string) (*model.TopUpSchedule, error) {
+	schedule, err := s.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != model.SchedulePaused {
+		return nil, model.Conflict("schedule is not paused")
+	}
+
+	next, err := nextRun(schedule.CronExpr, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.UpdateScheduleStatus(scheduleID, model.SchedulePaused, model.ScheduleActive, next); err != nil {
+		s.logger.Error("resume schedule error:", err)
+		return nil, storeError(err)
+	}
+
+	schedule.Status = model.ScheduleActive
+	schedule.NextRunAt = next
+	s.logger.Infof("schedule resumed: %s", scheduleID)
+	return schedule, nil
+}
+
+func (s *ScheduleService) ListRuns(ctx context.Context, scheduleID string) ([]model.ScheduleRun, error) {
+	if _, err := s.GetSchedule(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.ListRuns(scheduleID, scheduleRunHistory)
+}
+
+// RunDueSchedules tops up every active schedule whose next run time has
+// passed. Each schedule is claimed first so that concurrent workers never run
+// it twice. It returns the number of schedules that were run.
+func (s *ScheduleService) RunDueSchedules(ctx context.Context) (int, error) {
+	now := time.Now()
+	schedules, err := s.scheduleRepo.ListDueSchedules(now, dueScheduleBatchSize)
+	if err != nil {
+		s.logger.Error("list due schedules error:", err)
+		return 0, err
+	}
+
+	ran := 0
+	for i := range schedules {
+		schedule := &schedules[i]
+		if err := s.scheduleRepo.ClaimSchedule(schedule.ScheduleID, schedule.NextRunAt, now.Add(scheduleLease)); err != nil {
+			continue
+		}
+		s.run(ctx, schedule)
+		ran++
+	}
+	return ran, nil
+}
+
+func (s *ScheduleService) run(ctx context.Context, schedule *model.TopUpSchedule) {
+	txn, err := s.charge(ctx, schedule)
+	schedule.PendingTransactionID = ""
+	if isUncredited(err) {
+		schedule.PendingTransactionID = txn.TransactionID
+	}
+
+	now := time.Now()
+	run := &model.ScheduleRun{
+		RunID:      uuid.New().String(),
+		ScheduleID: schedule.ScheduleID,
+		Attempt:    schedule.RetryCount + 1,
+		CreatedAt:  now,
+	}
+	if txn != nil {
+		run.TransactionID = txn.TransactionID
+	}
+
+	var nextErr error
+	switch {
+	case err == nil:
+		run.Status = model.RunSucceeded
+		schedule.RetryCount = 0
+		schedule.NextRunAt, nextErr = nextRun(schedule.CronExpr, now)
+	case schedule.FailurePolicy == model.FailurePolicyRetry && schedule.RetryCount < schedule.MaxRetries:
+		run.Status = model.RunFailed
+		run.Error = err.Error()
+		schedule.RetryCount++
+		schedule.NextRunAt = now.Add(time.Duration(schedule.RetryCount) * scheduleRetryBackoff)
+	default:
+		run.Status = model.RunSkipped
+		run.Error = err.Error()
+		schedule.RetryCount = 0
+		schedule.NextRunAt, nextErr = nextRun(schedule.CronExpr, now)
+	}
+	if nextErr != nil {
+		// The expression no longer has a future occurrence; park the schedule.
+		s.logger.Error("schedule has no next run:", schedule.ScheduleID)
+		schedule.NextRunAt = now.AddDate(100, 0, 0)
+	}
+	schedule.LastRunAt = &now
+
+	if err := s.scheduleRepo.CreateRun(run); err != nil {
+		s.logger.Error("failed to record schedule run:", err)
+	}
+	if err := s.scheduleRepo.UpdateScheduleProgress(schedule); err != nil {
+		s.logger.Error("failed to update schedule:", err)
+	}
+
+	if run.Status == model.RunSucceeded {
+		s.logger.Infof("scheduled top-up succeeded: %s", schedule.ScheduleID)
+	} else {
+		s.logger.Warnf("scheduled top-up %s %s: %s", schedule.ScheduleID, run.Status, run.Error)
+	}
+}
+
+// charge tops the wallet up for one run. A charge left uncredited by an
+// earlier run is confirmed or refunded first; only once it is refunded is the
+// customer charged again.
+func (s *ScheduleService) charge(ctx context.Context, schedule *model.TopUpSchedule) (*model.Transaction, error) {
+	if schedule.PendingTransactionID != "" {
+		txn, err := settleCharge(ctx, s.wallet, s.payments, schedule.PendingTransactionID)
+		if err == nil || isUncredited(err) {
+			return txn, err
+		}
+		s.logger.Warnf("schedule %s refunded transaction %s: %v", schedule.ScheduleID, txn.TransactionID, err)
+	}
+	return chargeTopUp(ctx, s.wallet, s.payments, schedule.UserID, schedule.Amount, schedule.PaymentMethod, schedule.PaymentToken)
+}
+
+func nextRun(expr string, after time.Time) (time.Time, error) {
+	sched, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := sched.Next(after)
+	if next.IsZero() {
+		return time.Time{}, model.Invalid("cron expression never fires")
+	}
+	return next, nil
+}
@@ -0,0 +1,241 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupScheduleService() (*mocks.ScheduleRepoMock, *mocks.WalletServiceMock, *mocks.PaymentProviderMock, model.ScheduleService) {
+	repo := new(mocks.ScheduleRepoMock)
+	wallet := new(mocks.WalletServiceMock)
+	payments := new(mocks.PaymentProviderMock)
+	return repo, wallet, payments, service.NewScheduleService(repo, wallet, payments, setupLogger())
+}
+
+func dueSchedule(policy string, retryCount int) model.TopUpSchedule {
+	return model.TopUpSchedule{
+		ScheduleID:    "s1",
+		UserID:        1,
+		Amount:        500,
+		PaymentMethod: "credit_card",
+		PaymentToken:  "tok_1",
+		CronExpr:      "0 9 1 * *",
+		FailurePolicy: policy,
+		MaxRetries:    2,
+		RetryCount:    retryCount,
+		Status:        model.ScheduleActive,
+		NextRunAt:     time.Now().Add(-time.Minute),
+	}
+}
+
+func TestCreateSchedule_ComputesNextRun(t *testing.T) {
+	repo, wallet, _, s := setupScheduleService()
+	wallet.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
+	repo.On("CreateSchedule", mock.Anything).Return(nil)
+
+	schedule, err := s.CreateSchedule(systemCtx(), &model.TopUpSchedule{
+		UserID: 1, Amount: 500, PaymentMethod: "credit_card", PaymentToken: "tok_1", CronExpr: "0 9 1 * *",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.ScheduleActive, schedule.Status)
+	assert.Equal(t, model.FailurePolicyRetry, schedule.FailurePolicy)
+	assert.Equal(t, service.DefaultScheduleRetries, schedule.MaxRetries)
+	assert.Equal(t, 1, schedule.NextRunAt.Day())
+	assert.Equal(t, 9, schedule.NextRunAt.Hour())
+}
+
+func TestCreateSchedule_InvalidCron(t *testing.T) {
+	_, wallet, _, s := setupScheduleService()
+	wallet.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
+
+	_, err := s.CreateSchedule(systemCtx(), &model.TopUpSchedule{
+		UserID: 1, Amount: 500, PaymentToken: "tok_1", CronExpr: "every month",
+	})
+
+	assert.Error(t, err)
+}
+
+func TestRunDueSchedules_Success(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{dueSchedule(model.FailurePolicyRetry, 0)}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("VerifyTransaction", mock.Anything, uint(1), 500.0, "credit_card").Return(&model.Transaction{TransactionID: "t1"}, nil)
+	payments.On("Charge", mock.Anything, uint(1), 500.0, "credit_card", "tok_1", "t1").Return("charge_1", nil)
+	wallet.On("ConfirmTransaction", mock.Anything, "t1").Return(&model.Transaction{TransactionID: "t1", Status: "completed"}, nil)
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	ran, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ran)
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunSucceeded, run.Status)
+	assert.Equal(t, "t1", run.TransactionID)
+	updated := repo.Calls[3].Arguments.Get(0).(*model.TopUpSchedule)
+	assert.True(t, updated.NextRunAt.After(time.Now()))
+}
+
+func TestRunDueSchedules_RetryOnFailure(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{dueSchedule(model.FailurePolicyRetry, 0)}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("VerifyTransaction", mock.Anything, uint(1), 500.0, "credit_card").Return(&model.Transaction{TransactionID: "t1"}, nil)
+	payments.On("Charge", mock.Anything, uint(1), 500.0, "credit_card", "tok_1", "t1").Return("", errors.New("payment declined"))
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	_, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	wallet.AssertNotCalled(t, "ConfirmTransaction", mock.Anything, mock.Anything)
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunFailed, run.Status)
+	updated := repo.Calls[3].Arguments.Get(0).(*model.TopUpSchedule)
+	assert.Equal(t, 1, updated.RetryCount)
+}
+
+func TestRunDueSchedules_SkipAfterRetriesExhausted(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{dueSchedule(model.FailurePolicyRetry, 2)}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("VerifyTransaction", mock.Anything, uint(1), 500.0, "credit_card").Return(&model.Transaction{TransactionID: "t1"}, nil)
+	payments.On("Charge", mock.Anything, uint(1), 500.0, "credit_card", "tok_1", "t1").Return("", errors.New("payment declined"))
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	_, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunSkipped, run.Status)
+	assert.Equal(t, 3, run.Attempt)
+	updated := repo.Calls[3].Arguments.Get(0).(*model.TopUpSchedule)
+	assert.Equal(t, 0, updated.RetryCount)
+	assert.Equal(t, 1, updated.NextRunAt.Day())
+}
+
+func TestRunDueSchedules_ConfirmFailureKeepsCharge(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{dueSchedule(model.FailurePolicyRetry, 0)}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("VerifyTransaction", mock.Anything, uint(1), 500.0, "credit_card").Return(&model.Transaction{TransactionID: "t1"}, nil)
+	payments.On("Charge", mock.Anything, uint(1), 500.0, "credit_card", "tok_1", "t1").Return("charge_1", nil)
+	wallet.On("ConfirmTransaction", mock.Anything, "t1").Return((*model.Transaction)(nil), model.Unavailable("database unavailable", nil))
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	_, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunFailed, run.Status)
+	assert.Equal(t, "t1", run.TransactionID)
+	updated := repo.Calls[3].Arguments.Get(0).(*model.TopUpSchedule)
+	assert.Equal(t, "t1", updated.PendingTransactionID)
+}
+
+func TestRunDueSchedules_RetryConfirmsPendingCharge(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	schedule := dueSchedule(model.FailurePolicyRetry, 1)
+	schedule.PendingTransactionID = "t1"
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{schedule}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("ConfirmTransaction", mock.Anything, "t1").Return(&model.Transaction{TransactionID: "t1", Status: "completed"}, nil)
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	_, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	payments.AssertNotCalled(t, "Charge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunSucceeded, run.Status)
+	updated := repo.Calls[3].Arguments.Get(0).(*model.TopUpSchedule)
+	assert.Empty(t, updated.PendingTransactionID)
+}
+
+func TestRunDueSchedules_RetryRefundsExpiredCharge(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	schedule := dueSchedule(model.FailurePolicyRetry, 1)
+	schedule.PendingTransactionID = "t1"
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{schedule}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("ConfirmTransaction", mock.Anything, "t1").Return((*model.Transaction)(nil), model.Expired("transaction has expired"))
+	payments.On("Refund", mock.Anything, "t1").Return(nil)
+	wallet.On("VerifyTransaction", mock.Anything, uint(1), 500.0, "credit_card").Return(&model.Transaction{TransactionID: "t2"}, nil)
+	payments.On("Charge", mock.Anything, uint(1), 500.0, "credit_card", "tok_1", "t2").Return("charge_2", nil)
+	wallet.On("ConfirmTransaction", mock.Anything, "t2").Return(&model.Transaction{TransactionID: "t2", Status: "completed"}, nil)
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	_, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	payments.AssertCalled(t, "Refund", mock.Anything, "t1")
+	payments.AssertNotCalled(t, "Charge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, "t1")
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunSucceeded, run.Status)
+	assert.Equal(t, "t2", run.TransactionID)
+}
+
+func TestRunDueSchedules_RetryKeepsChargeWhileUnavailable(t *testing.T) {
+	repo, wallet, payments, s := setupScheduleService()
+	schedule := dueSchedule(model.FailurePolicyRetry, 1)
+	schedule.PendingTransactionID = "t1"
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{schedule}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(nil)
+	wallet.On("ConfirmTransaction", mock.Anything, "t1").Return((*model.Transaction)(nil), model.Unavailable("database unavailable", nil))
+	repo.On("CreateRun", mock.Anything).Return(nil)
+	repo.On("UpdateScheduleProgress", mock.Anything).Return(nil)
+
+	_, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	payments.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything)
+	payments.AssertNotCalled(t, "Charge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	run := repo.Calls[2].Arguments.Get(0).(*model.ScheduleRun)
+	assert.Equal(t, model.RunFailed, run.Status)
+	updated := repo.Calls[3].Arguments.Get(0).(*model.TopUpSchedule)
+	assert.Equal(t, "t1", updated.PendingTransactionID)
+}
+
+func TestRunDueSchedules_AlreadyClaimed(t *testing.T) {
+	repo, wallet, _, s := setupScheduleService()
+	repo.On("ListDueSchedules", mock.Anything, mock.Anything).Return([]model.TopUpSchedule{dueSchedule(model.FailurePolicyRetry, 0)}, nil)
+	repo.On("ClaimSchedule", "s1", mock.Anything, mock.Anything).Return(model.ErrStatusChanged)
+
+	ran, err := s.RunDueSchedules(systemCtx())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ran)
+	wallet.AssertNotCalled(t, "VerifyTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPauseResumeSchedule(t *testing.T) {
+	repo, _, _, s := setupScheduleService()
+	active := dueSchedule(model.FailurePolicySkip, 0)
+	repo.On("GetScheduleByID", "s1").Return(&active, nil).Once()
+	repo.On("UpdateScheduleStatus", "s1", model.ScheduleActive, model.SchedulePaused, mock.Anything).Return(nil)
+
+	paused, err := s.PauseSchedule(systemCtx(), "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.SchedulePaused, paused.Status)
+
+	repo.On("GetScheduleByID", "s1").Return(paused, nil).Once()
+	repo.On("UpdateScheduleStatus", "s1", model.SchedulePaused, model.ScheduleActive, mock.Anything).Return(nil)
+
+	resumed, err := s.ResumeSchedule(systemCtx(), "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.ScheduleActive, resumed.Status)
+	assert.True(t, resumed.NextRunAt.After(time.Now()))
+}
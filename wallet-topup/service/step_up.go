@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"wallet-topup/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	OTPTTL         = 5 * time.Minute
+	MaxOTPAttempts = 5
+	// MaxOTPSends limits how many codes one transaction can trigger, so the
+	// confirm endpoint cannot be used to flood a customer's phone.
+	MaxOTPSends = 3
+)
+
+// stepUp holds the settings for confirming large top-ups with a one-time
+// code.
+type stepUp struct {
+	threshold float64
+	sender    model.OTPSender
+}
+
+// WithStepUp requires a one-time code, sent through sender, before top-ups
+// above threshold are credited. Confirms by background jobs, marked with
+// model.WithSystem, are not challenged.
+func WithStepUp(threshold float64, sender model.OTPSender) WalletOption {
+	return func(s *WalletService) {
+		s.stepUp = &stepUp{threshold: threshold, sender: sender}
+	}
+}
+
+func otpKey(transactionID string) string {
+	return "otp:" + transactionID
+}
+
+func otpAttemptsKey(transactionID string) string {
+	return "otp:attempts:" + transactionID
+}
+
+func otpSendsKey(transactionID string) string {
+	return "otp:sends:" + transactionID
+}
+
+func hashOTP(transactionID, code string) string {
+	sum := sha256.Sum256([]byte(transactionID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkStepUp returns nil when txn may be credited. Without a code it sends
+// one and returns a *model.ChallengeRequiredError; with a code it checks it.
+func (s *WalletService) checkStepUp(ctx context.Context, txn *model.Transaction, user *model.User, code string) error {
+	if s.stepUp == nil || txn.Amount <= s.stepUp.threshold {
+		return nil
+	}
+	p, ok := model.PrincipalFrom(ctx)
+	if !ok {
+		return model.ErrUnauthenticated
+	}
+	if p.IsSystem() {
+		return nil
+	}
+	if s.redis == nil {
+		return model.Unavailable("step-up verification unavailable", nil)
+	}
+	if code == "" {
+		return s.sendChallenge(ctx, txn, user)
+	}
+	return s.checkOTP(ctx, txn.TransactionID, code)
+}
+
+func (s *WalletService) sendChallenge(ctx context.Context, txn *model.Transaction, user *model.User) error {
+	channel, destination := "sms", user.Phone
+	if destination == "" {
+		channel, destination = "email", user.Email
+	}
+	if destination == "" {
+		s.logger.Warnf("step-up for %s impossible: user_id=%d has no phone or email", txn.TransactionID, user.UserID)
+		return model.Conflict("no phone or email on file for step-up verification")
+	}
+
+	sends, err := s.redis.Incr(ctx, otpSendsKey(txn.TransactionID)).Result()
+	if err != nil {
+		s.logger.Error("otp send counter error:", err)
+		return storeError(err)
+	}
+	if sends == 1 {
+		s.redis.Expire(ctx, otpSendsKey(txn.TransactionID), s.maxLifetime)
+	}
+	if sends > MaxOTPSends {
+		s.logger.Warnf("otp send limit reached for %s", txn.TransactionID)
+		return model.ErrOTPRateLimited
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	if err := s.redis.Set(ctx, otpKey(txn.TransactionID), hashOTP(txn.TransactionID, code), OTPTTL).Err(); err != nil {
+		s.logger.Error("save otp error:", err)
+		return storeError(err)
+	}
+	s.redis.Del(ctx, otpAttemptsKey(txn.TransactionID))
+
+	if err := s.stepUp.sender.SendOTP(ctx, channel, destination, code); err != nil {
+		s.logger.Error("send otp error:", err)
+		return model.Unavailable("failed to send one-time code", nil)
+	}
+
+	s.logger.Infof("step-up challenge sent for %s via %s", txn.TransactionID, channel)
+	return &model.ChallengeRequiredError{
+		TransactionID: txn.TransactionID,
+		Channel:       channel,
+		Destination:   maskDestination(destination),
+		ExpiresAt:     time.Now().Add(OTPTTL),
+	}
+}
+
+// checkOTP accepts code at most once. After MaxOTPAttempts wrong codes the
+// code is thrown away and a new one has to be requested.
+func (s *WalletService) checkOTP(ctx context.Context, transactionID, code string) error {
+	attempts, err := s.redis.Incr(ctx, otpAttemptsKey(transactionID)).Result()
+	if err != nil {
+		s.logger.Error("otp attempt counter error:", err)
+		return storeError(err)
+	}
+	if attempts == 1 {
+		s.redis.Expire(ctx, otpAttemptsKey(transactionID), OTPTTL)
+	}
+	if attempts > MaxOTPAttempts {
+		s.redis.Del(ctx, otpKey(transactionID))
+		s.logger.Warnf("otp attempts exceeded for %s", transactionID)
+		return model.ErrOTPAttemptsExceeded
+	}
+
+	stored, err := s.redis.Get(ctx, otpKey(transactionID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return model.ErrInvalidOTP
+	}
+	if err != nil {
+		s.logger.Error("load otp error:", err)
+		return storeError(err)
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashOTP(transactionID, code))) != 1 {
+		s.logger.Warnf("wrong otp for %s (attempt %d)", transactionID, attempts)
+		return model.ErrInvalidOTP
+	}
+
+	s.redis.Del(ctx, otpKey(transactionID), otpAttemptsKey(transactionID))
+	return nil
+}
+
+// maskDestination keeps just enough of a phone number or email address for
+// the customer to recognise it.
+func maskDestination(destination string) string {
+	if local, domain, ok := strings.Cut(destination, "@"); ok {
+		if len(local) <= 1 {
+			return local + "***@" + domain
+		}
+		return local[:1] + strings.Repeat("*", len(local)-1) + "@" + domain
+	}
+	if len(destination) <= 6 {
+		return strings.Repeat("*", len(destination))
+	}
+	return destination[:3] + strings.Repeat("*", len(destination)-6) + destination[len(destination)-3:]
+}
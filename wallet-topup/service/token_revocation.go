@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-topup/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationList keeps revoked access tokens in Redis. A single token is
+// revoked by its jti until it expires. Logging out everywhere revokes every
+// token a subject was issued before a cutoff.
+type RevocationList struct {
+	redis RedisClient
+}
+
+func NewRevocationList(redis RedisClient) *RevocationList {
+	return &RevocationList{redis: redis}
+}
+
+func revokedTokenKey(jti string) string {
+	return "revoked:jti:" + jti
+}
+
+func revokedSubjectKey(subject string) string {
+	return "revoked:sub:" + subject
+}
+
+// Revoke blocks the token with id jti until expiresAt, after which it is
+// rejected anyway.
+func (l *RevocationList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return l.redis.Set(ctx, revokedTokenKey(jti), 1, ttl).Err()
+}
+
+// RevokeSubject blocks every token and session the subject was issued before
+// at. The cutoff has one-second precision, so a token issued in the same
+// second as the logout stays valid until it expires.
+func (l *RevocationList) RevokeSubject(ctx context.Context, subject string, at time.Time) error {
+	return l.redis.Set(ctx, revokedSubjectKey(subject), at.Unix(), RefreshTokenTTL).Err()
+}
+
+func (l *RevocationList) revokedBefore(ctx context.Context, subject string) (int64, error) {
+	cutoff, err := l.redis.Get(ctx, revokedSubjectKey(subject)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return cutoff, err
+}
+
+func (l *RevocationList) IsRevoked(ctx context.Context, p *model.Principal) (bool, error) {
+	if p.TokenID != "" {
+		err := l.redis.Get(ctx, revokedTokenKey(p.TokenID)).Err()
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			return false, err
+		}
+	}
+
+	cutoff, err := l.revokedBefore(ctx, p.Subject)
+	if err != nil {
+		return false, err
+	}
+	return p.IssuedAt.Unix() < cutoff, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"wallet-topup/model"
+)
+
+// uncreditedError reports that the customer was charged for a top-up but the
+// transaction could not be confirmed. The charge must be confirmed again or
+// refunded; charging anew would take the money twice.
+type uncreditedError struct {
+	err error
+}
+
+func (e *uncreditedError) Error() string {
+	return "charged but not credited: " + e.err.Error()
+}
+
+func (e *uncreditedError) Unwrap() error {
+	return e.err
+}
+
+func isUncredited(err error) bool {
+	var u *uncreditedError
+	return errors.As(err, &u)
+}
+
+// chargeTopUp runs the regular verify/confirm flow for a top-up the customer is
+// not present for, charging their saved payment method in between. If the
+// charge fails the verified transaction is left to expire and is returned
+// together with the error. If the charge succeeds but the confirm fails, the
+// transaction is returned with an *uncreditedError.
+func chargeTopUp(
+	ctx context.Context,
+	wallet model.WalletService,
+	payments model.PaymentProvider,
+	userID uint,
+	amount float64,
+	method, token string,
+) (*model.Transaction, error) {
+	txn, err := wallet.VerifyTransaction(ctx, userID, amount, method)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := payments.Charge(ctx, userID, amount, method, token, txn.TransactionID); err != nil {
+		return txn, err
+	}
+	confirmed, err := wallet.ConfirmTransaction(ctx, txn.TransactionID)
+	if err != nil {
+		return txn, &uncreditedError{err: err}
+	}
+	return confirmed, nil
+}
+
+// settleCharge finishes a top-up whose charge went through but whose confirm
+// failed, keyed on its transaction ID. The transaction is confirmed again; if
+// the confirm is refused for good the charge is refunded. A confirm that fails
+// for a reason that may pass, such as the database being unavailable, may
+// still have committed, so the charge is left for the next attempt rather than
+// refunded. It returns an *uncreditedError while the customer is still out of
+// pocket.
+func settleCharge(ctx context.Context, wallet model.WalletService, payments model.PaymentProvider, transactionID string) (*model.Transaction, error) {
+	txn, err := wallet.ConfirmTransaction(ctx, transactionID)
+	if err == nil {
+		return txn, nil
+	}
+	if model.KindOf(err) == model.KindAlreadyCompleted {
+		return &model.Transaction{TransactionID: transactionID, Status: "completed"}, nil
+	}
+	if !confirmRefused(err) {
+		return &model.Transaction{TransactionID: transactionID}, &uncreditedError{err: err}
+	}
+	if refundErr := payments.Refund(ctx, transactionID); refundErr != nil {
+		return &model.Transaction{TransactionID: transactionID}, &uncreditedError{err: refundErr}
+	}
+	return &model.Transaction{TransactionID: transactionID}, err
+}
+
+// confirmRefused reports whether a confirm error means the transaction will
+// never be credited: it expired, is gone, or the wallet or its limits no
+// longer allow it. ErrStatusChanged is not final, because it means another
+// request changed the transaction and may have confirmed it.
+func confirmRefused(err error) bool {
+	if errors.Is(err, model.ErrStatusChanged) {
+		return false
+	}
+	switch model.KindOf(err) {
+	case model.KindExpired, model.KindNotFound, model.KindConflict, model.KindLimitExceeded, model.KindForbidden, model.KindInvalid:
+		return true
+	}
+	return false
+}
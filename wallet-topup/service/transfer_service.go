@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxTransferAmount  = 50000.00
+	DailyTransferLimit = 200000.00
+)
+
+type TransferService struct {
+	txManager model.TxManager
+	logger    logs.Logger
+}
+
+func NewTransferService(txManager model.TxManager, logger logs.Logger) model.TransferService {
+	return &TransferService{
+		txManager: txManager,
+		logger:    logger,
+	}
+}
+
+func (s *TransferService) CreateTransfer(ctx context.Context, fromUserID, toUserID uint, amount float64, note string) (*model.Transfer, error) {
+	if _, err := authorizeWallet(ctx, s.logger, fromUserID, "transfer"); err != nil {
+		return nil, err
+	}
+	if fromUserID == toUserID {
+		return nil, model.Invalid("cannot transfer to the same wallet")
+	}
+	if amount <= 0 {
+		s.logger.Warnf("invalid transfer amount %.2f from user_id=%d", amount, fromUserID)
+		return nil, model.Invalid("amount must be greater than zero")
+	}
+	if amount > MaxTransferAmount {
+		s.logger.Warnf("transfer amount %.2f exceeds limit for user_id=%d", amount, fromUserID)
+		return nil, model.LimitExceeded("amount exceeds maximum allowed")
+	}
+
+	now := time.Now()
+	transfer := &model.Transfer{
+		TransferID: uuid.New().String(),
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Amount:     amount,
+		Note:       note,
+		Status:     "completed",
+		CreatedAt:  now,
+	}
+
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		// Always lock the lower user ID first so two opposite transfers
+		// between the same wallets cannot deadlock each other.
+		first, second := fromUserID, toUserID
+		if second < first {
+			first, second = second, first
+		}
+		for _, id := range []uint{first, second} {
+			user, err := repos.Users.GetUserByIDForUpdate(id)
+			if err != nil {
+				if id == fromUserID {
+					return lookupError(err, "sender")
+				}
+				return lookupError(err, "recipient")
+			}
+			if err := user.CheckActive(); err != nil {
+				if id == fromUserID {
+					return err
+				}
+				return fmt.Errorf("recipient %w", err)
+			}
+			tier := model.TierFor(user.KYCTier)
+			if id == fromUserID && !tier.Transfers {
+				return model.LimitExceeded("transfers are not available for kyc tier " + tier.Name)
+			}
+			if id == toUserID && user.Balance+amount > tier.MaxBalance {
+				return model.LimitExceeded("transfer would exceed recipient's kyc tier balance limit")
+			}
+		}
+
+		sent, err := repos.Transfers.SumOutgoingSince(fromUserID, startOfDay(now))
+		if err != nil {
+			return err
+		}
+		if sent+amount > DailyTransferLimit {
+			return model.LimitExceeded("daily transfer limit exceeded")
+		}
+
+		if err := repos.Users.DebitUserBalance(fromUserID, amount); err != nil {
+			return err
+		}
+		if err := repos.Users.UpdateUserBalance(toUserID, amount); err != nil {
+			return err
+		}
+		if err := repos.Transfers.CreateTransfer(transfer); err != nil {
+			return err
+		}
+		return repos.Ledger.CreateEntries(
+			&model.LedgerEntry{
+				EntryID:   uuid.New().String(),
+				UserID:    fromUserID,
+				Amount:    -amount,
+				Type:      "transfer_out",
+				Reference: transfer.TransferID,
+				CreatedAt: now,
+			},
+			&model.LedgerEntry{
+				EntryID:   uuid.New().String(),
+				UserID:    toUserID,
+				Amount:    amount,
+				Type:      "transfer_in",
+				Reference: transfer.TransferID,
+				CreatedAt: now,
+			},
+		)
+	})
+	if err != nil {
+		s.logger.Warnf("transfer from user_id=%d to user_id=%d failed: %v", fromUserID, toUserID, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("transfer completed: %s", transfer.TransferID)
+	return transfer, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
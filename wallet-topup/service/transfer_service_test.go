@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupTransferService() (*mocks.UserRepoMock, *mocks.TransferRepoMock, *mocks.LedgerRepoMock, model.TransferService) {
+	userRepo := new(mocks.UserRepoMock)
+	transferRepo := new(mocks.TransferRepoMock)
+	ledgerRepo := new(mocks.LedgerRepoMock)
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:     userRepo,
+		Transfers: transferRepo,
+		Ledger:    ledgerRepo,
+	}}
+	return userRepo, transferRepo, ledgerRepo, service.NewTransferService(txManager, setupLogger())
+}
+
+func TestCreateTransfer_Success(t *testing.T) {
+	userRepo, transferRepo, ledgerRepo, s := setupTransferService()
+
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500, KYCTier: model.KYCVerified}, nil)
+	userRepo.On("GetUserByIDForUpdate", uint(2)).Return(&model.User{UserID: 2, KYCTier: model.KYCVerified}, nil)
+	transferRepo.On("SumOutgoingSince", uint(2), mock.Anything).Return(0.0, nil)
+	userRepo.On("DebitUserBalance", uint(2), 100.0).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(1), 100.0).Return(nil)
+	transferRepo.On("CreateTransfer", mock.Anything).Return(nil)
+	ledgerRepo.On("CreateEntries", mock.Anything).Return(nil)
+
+	transfer, err := s.CreateTransfer(systemCtx(), 2, 1, 100.0, "dinner")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), transfer.FromUserID)
+	assert.Equal(t, uint(1), transfer.ToUserID)
+	assert.Equal(t, "completed", transfer.Status)
+	userRepo.AssertExpectations(t)
+
+	entries := ledgerRepo.Calls[0].Arguments.Get(0).([]*model.LedgerEntry)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, -100.0, entries[0].Amount)
+	assert.Equal(t, 100.0, entries[1].Amount)
+	assert.Equal(t, transfer.TransferID, entries[0].Reference)
+	assert.Equal(t, transfer.TransferID, entries[1].Reference)
+}
+
+func TestCreateTransfer_SameWallet(t *testing.T) {
+	_, _, _, s := setupTransferService()
+
+	_, err := s.CreateTransfer(systemCtx(), 1, 1, 100.0, "")
+	assert.EqualError(t, err, "cannot transfer to the same wallet")
+}
+
+func TestCreateTransfer_InvalidAmount(t *testing.T) {
+	_, _, _, s := setupTransferService()
+
+	_, err := s.CreateTransfer(systemCtx(), 1, 2, 0, "")
+	assert.EqualError(t, err, "amount must be greater than zero")
+
+	_, err = s.CreateTransfer(systemCtx(), 1, 2, service.MaxTransferAmount+1, "")
+	assert.EqualError(t, err, "amount exceeds maximum allowed")
+}
+
+func TestCreateTransfer_InsufficientFunds(t *testing.T) {
+	userRepo, transferRepo, _, s := setupTransferService()
+
+	userRepo.On("GetUserByIDForUpdate", mock.Anything).Return(&model.User{KYCTier: model.KYCVerified}, nil)
+	transferRepo.On("SumOutgoingSince", uint(1), mock.Anything).Return(0.0, nil)
+	userRepo.On("DebitUserBalance", uint(1), 100.0).Return(model.ErrInsufficientFunds)
+
+	_, err := s.CreateTransfer(systemCtx(), 1, 2, 100.0, "")
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	transferRepo.AssertNotCalled(t, "CreateTransfer", mock.Anything)
+}
+
+func TestCreateTransfer_DailyLimitExceeded(t *testing.T) {
+	userRepo, transferRepo, _, s := setupTransferService()
+
+	userRepo.On("GetUserByIDForUpdate", mock.Anything).Return(&model.User{KYCTier: model.KYCVerified}, nil)
+	transferRepo.On("SumOutgoingSince", uint(1), mock.Anything).Return(service.DailyTransferLimit-50, nil)
+
+	_, err := s.CreateTransfer(systemCtx(), 1, 2, 100.0, "")
+	assert.EqualError(t, err, "daily transfer limit exceeded")
+	userRepo.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestCreateTransfer_RecipientFrozen(t *testing.T) {
+	userRepo, _, _, s := setupTransferService()
+
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500, KYCTier: model.KYCVerified}, nil)
+	userRepo.On("GetUserByIDForUpdate", uint(2)).Return(&model.User{UserID: 2, Status: model.WalletFrozen}, nil)
+
+	_, err := s.CreateTransfer(systemCtx(), 1, 2, 100.0, "")
+	assert.ErrorIs(t, err, model.ErrWalletFrozen)
+	assert.EqualError(t, err, "recipient wallet is frozen")
+	userRepo.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
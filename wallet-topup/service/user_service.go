@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+)
+
+const maxFullNameLength = 200
+
+var phonePattern = regexp.MustCompile(`^\+[0-9]{8,15}$`)
+
+type UserService struct {
+	userRepo model.UserRepository
+	logger   logs.Logger
+}
+
+func NewUserService(userRepo model.UserRepository, logger logs.Logger) model.UserService {
+	return &UserService{
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// CreateUser provisions a wallet owner. Calling it again with the same
+// external reference returns the existing user with created=false.
+func (s *UserService) CreateUser(ctx context.Context, user *model.User) (*model.User, bool, error) {
+	ref, err := normalizeExternalRef(user.ExternalRef)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing, err := s.userRepo.GetUserByExternalRef(ref); err == nil {
+		return existing, false, nil
+	}
+
+	user.ExternalRef = ref
+	if err := normalizeProfile(user); err != nil {
+		return nil, false, err
+	}
+	if user.Email == "" && strings.Contains(ref, "@") {
+		user.Email = ref
+	}
+	if user.Phone == "" && !strings.Contains(ref, "@") {
+		user.Phone = ref
+	}
+
+	now := time.Now()
+	user.UserID = 0
+	user.Balance = 0
+	user.Status = model.WalletActive
+	user.KYCTier = model.KYCBasic
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	created, err := s.userRepo.CreateUser(user)
+	if err != nil {
+		s.logger.Error("create user error:", err)
+		return nil, false, storeError(err)
+	}
+	if !created {
+		// Lost a race with a concurrent create for the same reference.
+		existing, err := s.userRepo.GetUserByExternalRef(ref)
+		if err != nil {
+			return nil, false, storeError(err)
+		}
+		return existing, false, nil
+	}
+
+	s.logger.Infof("user created: user_id=%d", user.UserID)
+	return user, true, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, userID uint) (*model.User, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "read profile"); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, lookupError(err, "user")
+	}
+	return user, nil
+}
+
+func (s *UserService) FindByExternalRef(ctx context.Context, externalRef string) (*model.User, error) {
+	ref, err := normalizeExternalRef(externalRef)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByExternalRef(ref)
+	if err != nil {
+		return nil, lookupError(err, "user")
+	}
+	return user, nil
+}
+
+// UpdateProfile changes name and contact details. The external reference is
+// fixed at creation and cannot be edited here.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, update model.ProfileUpdate) (*model.User, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "update profile"); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, lookupError(err, "user")
+	}
+
+	if update.FullName != nil {
+		user.FullName = *update.FullName
+	}
+	if update.Email != nil {
+		user.Email = *update.Email
+	}
+	if update.Phone != nil {
+		user.Phone = *update.Phone
+	}
+	if err := normalizeProfile(user); err != nil {
+		return nil, err
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.UpdateUserProfile(user); err != nil {
+		s.logger.Error("update profile error:", err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("profile updated: user_id=%d", userID)
+	return user, nil
+}
+
+func normalizeProfile(user *model.User) error {
+	user.FullName = strings.TrimSpace(user.FullName)
+	if len(user.FullName) > maxFullNameLength {
+		return model.Invalid("full_name is too long")
+	}
+	if user.Email != "" {
+		email, err := normalizeEmail(user.Email)
+		if err != nil {
+			return err
+		}
+		user.Email = email
+	}
+	if user.Phone != "" {
+		phone, err := normalizePhone(user.Phone)
+		if err != nil {
+			return err
+		}
+		user.Phone = phone
+	}
+	return nil
+}
+
+// normalizeExternalRef accepts an email address or a phone number and returns
+// it in the canonical form used for lookups.
+func normalizeExternalRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", model.Invalid("external_ref is required")
+	}
+	if strings.Contains(ref, "@") {
+		return normalizeEmail(ref)
+	}
+	return normalizePhone(ref)
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", model.Invalid("invalid email address")
+	}
+	return email, nil
+}
+
+// normalizePhone strips separators and converts Thai local numbers such as
+// 081-234-5678 to E.164 (+66812345678).
+func normalizePhone(phone string) (string, error) {
+	phone = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(phone)
+	if strings.HasPrefix(phone, "0") && len(phone) == 10 {
+		phone = "+66" + phone[1:]
+	}
+	if !phonePattern.MatchString(phone) {
+		return "", model.Invalid("invalid phone number")
+	}
+	return phone, nil
+}
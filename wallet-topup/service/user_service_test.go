@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateUser_Success(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	s := service.NewUserService(userRepo, setupLogger())
+
+	userRepo.On("GetUserByExternalRef", "+66812345678").Return((*model.User)(nil), errors.New("not found"))
+	userRepo.On("CreateUser", mock.Anything).Return(true, nil)
+
+	user, created, err := s.CreateUser(systemCtx(), &model.User{
+		ExternalRef: "081-234-5678",
+		FullName:    " Somchai Jaidee ",
+		Email:       "Somchai@Example.com",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "+66812345678", user.ExternalRef)
+	assert.Equal(t, "+66812345678", user.Phone)
+	assert.Equal(t, "somchai@example.com", user.Email)
+	assert.Equal(t, "Somchai Jaidee", user.FullName)
+	assert.Equal(t, model.WalletActive, user.Status)
+	assert.Equal(t, model.KYCBasic, user.KYCTier)
+}
+
+func TestCreateUser_IdempotentOnExternalRef(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	s := service.NewUserService(userRepo, setupLogger())
+
+	existing := &model.User{UserID: 7, ExternalRef: "somchai@example.com"}
+	userRepo.On("GetUserByExternalRef", "somchai@example.com").Return(existing, nil)
+
+	user, created, err := s.CreateUser(systemCtx(), &model.User{ExternalRef: "SOMCHAI@example.com"})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, uint(7), user.UserID)
+	userRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestCreateUser_ConcurrentDuplicate(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	s := service.NewUserService(userRepo, setupLogger())
+
+	existing := &model.User{UserID: 7, ExternalRef: "somchai@example.com"}
+	userRepo.On("GetUserByExternalRef", "somchai@example.com").Return((*model.User)(nil), errors.New("not found")).Once()
+	userRepo.On("CreateUser", mock.Anything).Return(false, nil)
+	userRepo.On("GetUserByExternalRef", "somchai@example.com").Return(existing, nil)
+
+	user, created, err := s.CreateUser(systemCtx(), &model.User{ExternalRef: "somchai@example.com"})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, uint(7), user.UserID)
+}
+
+func TestCreateUser_InvalidExternalRef(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	s := service.NewUserService(userRepo, setupLogger())
+
+	_, _, err := s.CreateUser(systemCtx(), &model.User{ExternalRef: "12ab"})
+	assert.EqualError(t, err, "invalid phone number")
+
+	_, _, err = s.CreateUser(systemCtx(), &model.User{ExternalRef: "not an@email"})
+	assert.EqualError(t, err, "invalid email address")
+}
+
+func TestUpdateProfile_OnlyGivenFields(t *testing.T) {
+	userRepo := new(mocks.UserRepoMock)
+	s := service.NewUserService(userRepo, setupLogger())
+
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, FullName: "Old", Email: "old@example.com"}, nil)
+	userRepo.On("UpdateUserProfile", mock.Anything).Return(nil)
+
+	name := "New Name"
+	user, err := s.UpdateProfile(systemCtx(), 1, model.ProfileUpdate{FullName: &name})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New Name", user.FullName)
+	assert.Equal(t, "old@example.com", user.Email)
+}
@@ -12,75 +12,305 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	MaxTopUpAmount = 100000.00
+
+	// VerifyTTL is how long a verified transaction stays confirmable, and how
+	// much time each extension adds.
+	VerifyTTL = 15 * time.Minute
+	// DefaultMaxTransactionLifetime caps how far extensions can push
+	// ExpiresAt past the transaction's creation.
+	DefaultMaxTransactionLifetime = time.Hour
+)
+
 type RedisClient interface {
 	Get(ctx context.Context, key string) *redis.StringCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Incr(ctx context.Context, key string) *redis.IntCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
 }
 
 type WalletService struct {
-	txnRepo  model.TransactionRepository
-	userRepo model.UserRepository
-	redis    RedisClient
-	logger   logs.Logger
+	txManager   model.TxManager
+	txnRepo     model.TransactionRepository
+	userRepo    model.UserRepository
+	redis       RedisClient
+	logger      logs.Logger
+	quoteSecret []byte
+	maxLifetime time.Duration
+	auditRepo   model.AuditRepository
+	stepUp      *stepUp
+}
+
+// WalletOption configures optional WalletService settings.
+type WalletOption func(*WalletService)
+
+// WithQuoteSecret sets the key used to sign quote tokens. Without it a random
+// key is generated, so quotes do not survive a restart.
+func WithQuoteSecret(secret []byte) WalletOption {
+	return func(s *WalletService) {
+		if len(secret) > 0 {
+			s.quoteSecret = secret
+		}
+	}
+}
+
+// WithMaxTransactionLifetime sets how long after creation a verified
+// transaction can be kept alive through extensions.
+func WithMaxTransactionLifetime(d time.Duration) WalletOption {
+	return func(s *WalletService) {
+		if d >= VerifyTTL {
+			s.maxLifetime = d
+		}
+	}
+}
+
+// WithAuditRepository records top-ups that an admin or service verifies or
+// confirms on behalf of a customer.
+func WithAuditRepository(repo model.AuditRepository) WalletOption {
+	return func(s *WalletService) {
+		s.auditRepo = repo
+	}
 }
 
 func NewWalletService(
+	txManager model.TxManager,
 	txnRepo model.TransactionRepository,
 	userRepo model.UserRepository,
 	redis RedisClient,
 	logger logs.Logger,
+	opts ...WalletOption,
 ) model.WalletService {
-	return &WalletService{
-		txnRepo:  txnRepo,
-		userRepo: userRepo,
-		redis:    redis,
-		logger:   logger,
+	s := &WalletService{
+		txManager:   txManager,
+		txnRepo:     txnRepo,
+		userRepo:    userRepo,
+		redis:       redis,
+		logger:      logger,
+		quoteSecret: randomKey(),
+		maxLifetime: DefaultMaxTransactionLifetime,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// topUpTerms is the outcome of evaluating a top-up request: the limits it
+// passed and the price it would be charged at.
+type topUpTerms struct {
+	user           *model.User
+	amount         float64
+	method         string
+	fee            float64
+	bonus          float64
+	limitRemaining float64
+	now            time.Time
 }
 
 func (s *WalletService) VerifyTransaction(ctx context.Context, userID uint, amount float64, method string) (*model.Transaction, error) {
-	const MaxTopUpAmount = 100000.00
+	if p, ok := model.PrincipalFrom(ctx); ok && p.IsMerchant() {
+		s.logger.Warnf("verify denied: %s sent no quote for user_id=%d", p.Subject, userID)
+		return nil, model.ErrQuoteRequired
+	}
+	onBehalf, err := authorizeWallet(ctx, s.logger, userID, "verify")
+	if err != nil {
+		return nil, err
+	}
+	terms, err := s.evaluateTopUp(userID, amount, method)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := s.createVerified(ctx, terms)
+	if err == nil && onBehalf {
+		s.recordOnBehalf(ctx, "topup.verified_on_behalf", txn)
+	}
+	return txn, err
+}
 
-	_, err := s.userRepo.GetUserByID(userID)
+// QuoteTopUp runs the same checks as VerifyTransaction and returns the terms
+// with a signed token, but stores nothing.
+func (s *WalletService) QuoteTopUp(ctx context.Context, userID uint, amount float64, method string) (*model.Quote, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "quote"); err != nil {
+		return nil, err
+	}
+	terms, err := s.evaluateTopUp(userID, amount, method)
 	if err != nil {
-		s.logger.Error("user not found:", userID)
-		return nil, errors.New("user not found")
+		return nil, err
+	}
+
+	quote := &model.Quote{
+		QuoteID:        uuid.New().String(),
+		UserID:         userID,
+		Amount:         amount,
+		PaymentMethod:  method,
+		Fee:            terms.fee,
+		Bonus:          terms.bonus,
+		LimitRemaining: terms.limitRemaining,
+		ExpiresAt:      terms.now.Add(QuoteTTL),
+	}
+	quote.Token = signQuote(s.quoteSecret, quote)
+
+	s.logger.Infof("quote issued: %s", quote.QuoteID)
+	return quote, nil
+}
+
+// VerifyQuote creates a verified transaction for the amount and payment
+// method signed into a quote. Limits are checked again because the wallet may
+// have changed since the quote was issued, and the fee and bonus must still
+// be the quoted ones. Each quote can be used once; it is only claimed once
+// those checks pass, and released again if the transaction cannot be
+// created, so a failed attempt can be retried. It is the only way a merchant
+// API key can start a top-up.
+func (s *WalletService) VerifyQuote(ctx context.Context, quoteToken string) (*model.Transaction, error) {
+	quote, err := parseQuote(s.quoteSecret, quoteToken)
+	if err != nil {
+		s.logger.Warn("invalid quote token:", err)
+		return nil, err
+	}
+	onBehalf, err := authorizeQuote(ctx, s.logger, quote)
+	if err != nil {
+		return nil, err
+	}
+	ttl := time.Until(quote.ExpiresAt)
+	if ttl <= 0 {
+		return nil, model.Expired("quote expired")
+	}
+
+	terms, err := s.evaluateTopUp(quote.UserID, quote.Amount, quote.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+	if terms.fee != quote.Fee || terms.bonus != quote.Bonus {
+		s.logger.Warnf("quote %s terms changed: fee %.2f->%.2f bonus %.2f->%.2f", quote.QuoteID, quote.Fee, terms.fee, quote.Bonus, terms.bonus)
+		return nil, model.ErrQuoteTermsChanged
+	}
+
+	key := "quote:" + quote.QuoteID
+	if s.redis != nil {
+		ok, err := s.redis.SetNX(ctx, key, 1, ttl).Result()
+		if err != nil {
+			s.logger.Error("quote lock error:", err)
+			return nil, storeError(err)
+		}
+		if !ok {
+			return nil, model.AlreadyCompleted("quote already used")
+		}
+	}
+
+	txn, err := s.createVerified(ctx, terms)
+	if err != nil {
+		if s.redis != nil {
+			s.redis.Del(ctx, key)
+		}
+		return nil, err
+	}
+	if onBehalf {
+		s.recordOnBehalf(ctx, "topup.verified_on_behalf", txn)
+	}
+	return txn, nil
+}
+
+func (s *WalletService) evaluateTopUp(userID uint, amount float64, method string) (*topUpTerms, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		s.logger.Error("load user error:", err)
+		return nil, lookupError(err, "user")
+	}
+	if err := user.CheckActive(); err != nil {
+		s.logger.Warnf("verify refused for user_id=%d: %v", userID, err)
+		return nil, err
 	}
 
 	if amount <= 0 {
 		s.logger.Warnf("invalid amount %.2f for user_id=%d", amount, userID)
-		return nil, errors.New("amount must be greater than zero")
+		return nil, model.Invalid("amount must be greater than zero")
 	}
 	if amount > MaxTopUpAmount {
 		s.logger.Warnf("amount %.2f exceeds limit for user_id=%d", amount, userID)
-		return nil, errors.New("amount exceeds maximum allowed")
+		return nil, model.LimitExceeded("amount exceeds maximum allowed")
 	}
 
+	now := time.Now()
+	remaining, err := s.checkTierLimits(user, amount, now)
+	if err != nil {
+		s.logger.Warnf("amount %.2f refused for user_id=%d: %v", amount, userID, err)
+		return nil, err
+	}
+
+	fee, bonus := topUpPricing(user, amount, method)
+	return &topUpTerms{
+		user:           user,
+		amount:         amount,
+		method:         method,
+		fee:            fee,
+		bonus:          bonus,
+		limitRemaining: remaining,
+		now:            now,
+	}, nil
+}
+
+func (s *WalletService) createVerified(ctx context.Context, terms *topUpTerms) (*model.Transaction, error) {
 	txn := &model.Transaction{
 		TransactionID: uuid.New().String(),
-		UserID:        userID,
-		Amount:        amount,
-		PaymentMethod: method,
+		UserID:        terms.user.UserID,
+		Amount:        terms.amount,
+		PaymentMethod: terms.method,
 		Status:        "verified",
-		ExpiresAt:     time.Now().Add(15 * time.Minute),
+		ExpiresAt:     terms.now.Add(VerifyTTL),
+		CreatedAt:     terms.now,
 	}
 
 	if err := s.txnRepo.CreateTransaction(txn); err != nil {
 		s.logger.Error("failed to create transaction:", err)
-		return nil, err
+		return nil, storeError(err)
 	}
 
 	data, _ := json.Marshal(txn)
 	if s.redis != nil {
-		s.redis.Set(ctx, "txn:"+txn.TransactionID, data, 15*time.Minute)
+		s.redis.Set(ctx, "txn:"+txn.TransactionID, data, VerifyTTL)
 	}
 
 	s.logger.Infof("transaction verified: %s", txn.TransactionID)
 	return txn, nil
 }
 
+func (s *WalletService) recordOnBehalf(ctx context.Context, action string, txn *model.Transaction) {
+	p, _ := model.PrincipalFrom(ctx)
+	if s.auditRepo == nil || p == nil {
+		return
+	}
+	entry := newAuditLog(p.Subject, action, "transaction", txn.TransactionID, map[string]interface{}{
+		"user_id": txn.UserID,
+		"amount":  txn.Amount,
+		"roles":   p.Roles,
+	})
+	if err := s.auditRepo.CreateAuditLog(entry); err != nil {
+		s.logger.Error("write audit log error:", err)
+	}
+}
+
+// topUpPricing returns the fee charged on top of amount and the promotional
+// bonus credited with it. No fees or promotions are configured yet, so both
+// are zero; this is the single place to add them.
+func topUpPricing(user *model.User, amount float64, method string) (fee, bonus float64) {
+	return 0, 0
+}
+
 func (s *WalletService) ConfirmTransaction(ctx context.Context, transactionID string) (*model.Transaction, error) {
+	return s.confirm(ctx, transactionID, "")
+}
+
+func (s *WalletService) ConfirmWithCode(ctx context.Context, transactionID, code string) (*model.Transaction, error) {
+	if code == "" {
+		return nil, model.ErrInvalidOTP
+	}
+	return s.confirm(ctx, transactionID, code)
+}
+
+func (s *WalletService) confirm(ctx context.Context, transactionID, code string) (*model.Transaction, error) {
 	var val string
 	var err error
 	if s.redis != nil {
@@ -93,34 +323,170 @@ func (s *WalletService) ConfirmTransaction(ctx context.Context, transactionID st
 	} else {
 		dbTxn, err := s.txnRepo.GetTransactionByID(transactionID)
 		if err != nil {
-			s.logger.Error("transaction not found:", transactionID)
-			return nil, errors.New("transaction not found")
+			s.logger.Error("load transaction error:", err)
+			return nil, lookupError(err, "transaction")
 		}
 		txn = *dbTxn
 	}
 
-	if txn.Status != "verified" || time.Now().After(txn.ExpiresAt) {
-		s.logger.Warn("transaction expired or already confirmed:", transactionID)
-		return nil, errors.New("transaction expired or already completed")
+	onBehalf, err := authorizeWallet(ctx, s.logger, txn.UserID, "confirm")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPending(&txn, time.Now()); err != nil {
+		s.logger.Warnf("cannot confirm %s: %v", transactionID, err)
+		return nil, err
 	}
 
-	if err := s.txnRepo.UpdateTransactionStatus(transactionID, "completed"); err != nil {
-		s.logger.Error("update status error:", err)
+	user, err := s.userRepo.GetUserByID(txn.UserID)
+	if err != nil {
+		s.logger.Error("load user error:", err)
+		return nil, lookupError(err, "user")
+	}
+	if err := user.CheckActive(); err != nil {
+		s.logger.Warnf("confirm refused for user_id=%d: %v", txn.UserID, err)
 		return nil, err
 	}
-	if err := s.userRepo.UpdateUserBalance(txn.UserID, txn.Amount); err != nil {
-		s.logger.Error("update balance error:", err)
+	if err := s.checkStepUp(ctx, &txn, user, code); err != nil {
 		return nil, err
 	}
 
+	// The guarded status change makes racing confirms of the same
+	// transaction credit it once; the loser gets ErrStatusChanged.
+	err = s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Transactions.CompleteTransaction(transactionID); err != nil {
+			return err
+		}
+		if err := repos.Users.UpdateUserBalance(txn.UserID, txn.Amount); err != nil {
+			return err
+		}
+		return repos.Ledger.CreateEntries(&model.LedgerEntry{
+			EntryID:   uuid.New().String(),
+			UserID:    txn.UserID,
+			Amount:    txn.Amount,
+			Type:      "topup",
+			Reference: transactionID,
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrStatusChanged) {
+			s.logger.Warnf("confirm of %s lost a race: %v", transactionID, err)
+		} else {
+			s.logger.Error("confirm transaction error:", err)
+		}
+		return nil, storeError(err)
+	}
+
 	if s.redis != nil {
 		s.redis.Del(ctx, "txn:"+transactionID)
 	}
 	s.logger.Infof("transaction confirmed: %s", transactionID)
 	txn.Status = "completed"
+	if onBehalf {
+		s.recordOnBehalf(ctx, "topup.confirmed_on_behalf", &txn)
+	}
 	return &txn, nil
 }
 
+// ExtendTransaction gives a verified transaction another VerifyTTL, without
+// going past maxLifetime from when it was created. The database row is the
+// source of truth; the Redis copy is dropped so confirm reads the new row.
+func (s *WalletService) ExtendTransaction(ctx context.Context, transactionID string) (*model.Transaction, error) {
+	txn, err := s.txnRepo.GetTransactionByID(transactionID)
+	if err != nil {
+		s.logger.Error("load transaction error:", err)
+		return nil, lookupError(err, "transaction")
+	}
+	if _, err := authorizeWallet(ctx, s.logger, txn.UserID, "extend"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := checkPending(txn, now); err != nil {
+		s.logger.Warnf("cannot extend %s: %v", transactionID, err)
+		return nil, err
+	}
+
+	createdAt := txn.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = txn.ExpiresAt.Add(-VerifyTTL)
+	}
+	expiresAt := now.Add(VerifyTTL)
+	if limit := createdAt.Add(s.maxLifetime); expiresAt.After(limit) {
+		expiresAt = limit
+	}
+	if !expiresAt.After(txn.ExpiresAt) {
+		return nil, model.LimitExceeded("transaction has reached its maximum lifetime")
+	}
+
+	if err := s.txnRepo.ExtendTransaction(transactionID, expiresAt); err != nil {
+		if !errors.Is(err, model.ErrStatusChanged) {
+			s.logger.Error("extend transaction error:", err)
+		}
+		return nil, storeError(err)
+	}
+	txn.ExpiresAt = expiresAt
+	txn.Extensions++
+
+	if s.redis != nil {
+		s.redis.Del(ctx, "txn:"+transactionID)
+	}
+
+	s.logger.Infof("transaction extended: %s until %s", transactionID, expiresAt.Format(time.RFC3339))
+	return txn, nil
+}
+
+// checkPending returns nil while txn is verified and unexpired, and otherwise
+// says why it can no longer be confirmed or extended.
+func checkPending(txn *model.Transaction, now time.Time) error {
+	switch {
+	case txn.Status == "completed":
+		return model.AlreadyCompleted("transaction is already completed")
+	case txn.Status != "verified":
+		return model.Conflict("transaction is " + txn.Status)
+	case now.After(txn.ExpiresAt):
+		return model.Expired("transaction has expired")
+	}
+	return nil
+}
+
+// checkTierLimits applies the per-transaction, daily and balance limits of
+// the user's KYC tier to a new top-up and returns how much of the daily limit
+// would be left after it.
+func (s *WalletService) checkTierLimits(user *model.User, amount float64, now time.Time) (float64, error) {
+	if err := checkTierCaps(user, amount); err != nil {
+		return 0, err
+	}
+	tier := model.TierFor(user.KYCTier)
+	toppedUp, err := s.txnRepo.SumTopUpsSince(user.UserID, startOfDay(now))
+	if err != nil {
+		s.logger.Error("sum top-ups error:", err)
+		return 0, storeError(err)
+	}
+	if toppedUp+amount > tier.DailyLimit {
+		return 0, model.LimitExceeded("daily top-up limit exceeded").
+			WithDetails(map[string]any{"tier": tier.Name, "limit": tier.DailyLimit, "remaining": max(tier.DailyLimit-toppedUp, 0)})
+	}
+	return tier.DailyLimit - toppedUp - amount, nil
+}
+
+// checkTierCaps applies the per-transaction and balance limits of the user's
+// KYC tier to a credit of amount.
+func checkTierCaps(user *model.User, amount float64) error {
+	tier := model.TierFor(user.KYCTier)
+	if amount > tier.MaxTransaction {
+		return model.LimitExceeded("amount exceeds kyc tier transaction limit").
+			WithDetails(map[string]any{"tier": tier.Name, "limit": tier.MaxTransaction})
+	}
+	if user.Balance+amount > tier.MaxBalance {
+		return model.LimitExceeded("top-up would exceed kyc tier balance limit").
+			WithDetails(map[string]any{"tier": tier.Name, "limit": tier.MaxBalance})
+	}
+	return nil
+}
+
 func (s *WalletService) GetUserByID(userID uint) (*model.User, error) {
 	return s.userRepo.GetUserByID(userID)
 }
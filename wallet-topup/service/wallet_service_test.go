@@ -11,8 +11,10 @@ import (
 	"wallet-topup/service"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func setupLogger() *mocks.LoggerMock {
@@ -24,6 +26,26 @@ func setupLogger() *mocks.LoggerMock {
 	return logger
 }
 
+// walletTx runs confirms against the given repositories, with a ledger that
+// accepts any entry.
+func walletTx(txnRepo *mocks.TransactionRepoMock, userRepo *mocks.UserRepoMock) *mocks.TxManagerMock {
+	ledger := new(mocks.LedgerRepoMock)
+	ledger.On("CreateEntries", mock.Anything).Return(nil).Maybe()
+	return &mocks.TxManagerMock{Repos: model.TxRepositories{Users: userRepo, Transactions: txnRepo, Ledger: ledger}}
+}
+
+// systemCtx is the context of a background job, which may act on any wallet.
+func systemCtx() context.Context {
+	return model.WithSystem(context.Background(), "test")
+}
+
+// customerCtx is the context of a customer acting on their own wallet.
+func customerCtx(userID uint) context.Context {
+	return model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "alice", Roles: []string{model.RoleCustomer}, UserID: &userID,
+	})
+}
+
 func TestVerifyTransaction_Success(t *testing.T) {
 	txnRepo := new(mocks.TransactionRepoMock)
 	userRepo := new(mocks.UserRepoMock)
@@ -31,11 +53,12 @@ func TestVerifyTransaction_Success(t *testing.T) {
 	logger := setupLogger()
 
 	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
+	txnRepo.On("SumTopUpsSince", uint(1), mock.Anything).Return(0.0, nil)
 	txnRepo.On("CreateTransaction", mock.Anything).Return(nil)
 	redisMock.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	s := service.NewWalletService(txnRepo, userRepo, redisMock, logger)
-	txn, err := s.VerifyTransaction(context.Background(), 1, 100.0, "credit_card")
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger)
+	txn, err := s.VerifyTransaction(systemCtx(), 1, 100.0, "credit_card")
 
 	assert.NoError(t, err)
 	assert.Equal(t, uint(1), txn.UserID)
@@ -49,14 +72,27 @@ func TestVerifyTransaction_UserNotFound(t *testing.T) {
 	redisMock := new(mocks.RedisMock)
 	logger := setupLogger()
 
-	userRepo.On("GetUserByID", uint(99)).Return((*model.User)(nil), errors.New("user not found"))
+	userRepo.On("GetUserByID", uint(99)).Return((*model.User)(nil), gorm.ErrRecordNotFound)
 
-	s := service.NewWalletService(txnRepo, userRepo, redisMock, logger)
-	_, err := s.VerifyTransaction(context.Background(), 99, 100.0, "credit_card")
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger)
+	_, err := s.VerifyTransaction(systemCtx(), 99, 100.0, "credit_card")
 
 	assert.EqualError(t, err, "user not found")
 }
 
+func TestVerifyTransaction_DatabaseDownIsUnavailable(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	userRepo.On("GetUserByID", uint(1)).Return((*model.User)(nil), errors.New("connection refused"))
+
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := s.VerifyTransaction(systemCtx(), 1, 100.0, "credit_card")
+
+	assert.Equal(t, model.KindUnavailable, model.KindOf(err))
+}
+
 func TestVerifyTransaction_InvalidAmount(t *testing.T) {
 	txnRepo := new(mocks.TransactionRepoMock)
 	userRepo := new(mocks.UserRepoMock)
@@ -65,15 +101,56 @@ func TestVerifyTransaction_InvalidAmount(t *testing.T) {
 
 	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1}, nil)
 
-	s := service.NewWalletService(txnRepo, userRepo, redisMock, logger)
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger)
 
-	_, err := s.VerifyTransaction(context.Background(), 1, -5.0, "credit_card")
+	_, err := s.VerifyTransaction(systemCtx(), 1, -5.0, "credit_card")
 	assert.EqualError(t, err, "amount must be greater than zero")
 
-	_, err = s.VerifyTransaction(context.Background(), 1, 1000000.0, "credit_card")
+	_, err = s.VerifyTransaction(systemCtx(), 1, 1000000.0, "credit_card")
 	assert.EqualError(t, err, "amount exceeds maximum allowed")
 }
 
+func TestVerifyTransaction_WalletNotActive(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletFrozen}, nil)
+	userRepo.On("GetUserByID", uint(2)).Return(&model.User{UserID: 2, Status: model.WalletSuspended}, nil)
+
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+
+	_, err := s.VerifyTransaction(systemCtx(), 1, 100.0, "credit_card")
+	assert.ErrorIs(t, err, model.ErrWalletFrozen)
+
+	_, err = s.VerifyTransaction(systemCtx(), 2, 100.0, "credit_card")
+	assert.ErrorIs(t, err, model.ErrWalletSuspended)
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+}
+
+func TestVerifyTransaction_KYCTierLimits(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Balance: 4000, KYCTier: model.KYCBasic}, nil)
+	userRepo.On("GetUserByID", uint(2)).Return(&model.User{UserID: 2, KYCTier: model.KYCVerified}, nil)
+	txnRepo.On("SumTopUpsSince", uint(2), mock.Anything).Return(95000.0, nil)
+
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+
+	_, err := s.VerifyTransaction(systemCtx(), 1, 6000.0, "credit_card")
+	assert.EqualError(t, err, "amount exceeds kyc tier transaction limit")
+
+	_, err = s.VerifyTransaction(systemCtx(), 1, 2000.0, "credit_card")
+	assert.EqualError(t, err, "top-up would exceed kyc tier balance limit")
+
+	_, err = s.VerifyTransaction(systemCtx(), 2, 10000.0, "credit_card")
+	assert.EqualError(t, err, "daily top-up limit exceeded")
+	assert.Equal(t, model.KindLimitExceeded, model.KindOf(err))
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+}
+
 func TestConfirmTransaction_Success(t *testing.T) {
 	txnRepo := new(mocks.TransactionRepoMock)
 	userRepo := new(mocks.UserRepoMock)
@@ -89,17 +166,66 @@ func TestConfirmTransaction_Success(t *testing.T) {
 	}
 
 	txnRepo.On("GetTransactionByID", transactionID).Return(txn, nil)
-	txnRepo.On("UpdateTransactionStatus", transactionID, "completed").Return(nil)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletActive}, nil)
+	txnRepo.On("CompleteTransaction", transactionID).Return(nil)
 	userRepo.On("UpdateUserBalance", txn.UserID, txn.Amount).Return(nil)
 
-	svc := service.NewWalletService(txnRepo, userRepo, nil, logger)
-	res, err := svc.ConfirmTransaction(context.Background(), transactionID)
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	res, err := svc.ConfirmTransaction(systemCtx(), transactionID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "completed", res.Status)
 	assert.Equal(t, transactionID, res.TransactionID)
 }
 
+func TestConfirmTransaction_LosesRace(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	transactionID := uuid.New().String()
+	txnRepo.On("GetTransactionByID", transactionID).Return(&model.Transaction{
+		TransactionID: transactionID,
+		UserID:        1,
+		Amount:        100.0,
+		Status:        "verified",
+		ExpiresAt:     time.Now().Add(10 * time.Minute),
+	}, nil)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletActive}, nil)
+	txnRepo.On("CompleteTransaction", transactionID).Return(model.ErrStatusChanged)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := svc.ConfirmTransaction(systemCtx(), transactionID)
+
+	assert.ErrorIs(t, err, model.ErrStatusChanged)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestConfirmTransaction_WalletClosed(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	transactionID := uuid.New().String()
+	txn := &model.Transaction{
+		TransactionID: transactionID,
+		UserID:        1,
+		Amount:        100.0,
+		Status:        "verified",
+		ExpiresAt:     time.Now().Add(10 * time.Minute),
+	}
+
+	txnRepo.On("GetTransactionByID", transactionID).Return(txn, nil)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletClosed}, nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := svc.ConfirmTransaction(systemCtx(), transactionID)
+
+	assert.ErrorIs(t, err, model.ErrWalletClosed)
+	txnRepo.AssertNotCalled(t, "CompleteTransaction", mock.Anything)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
 func TestConfirmTransaction_Expired(t *testing.T) {
 
 	txnRepo := new(mocks.TransactionRepoMock)
@@ -117,12 +243,13 @@ func TestConfirmTransaction_Expired(t *testing.T) {
 
 	txnRepo.On("GetTransactionByID", transactionID).Return(expiredTxn, nil)
 
-	svc := service.NewWalletService(txnRepo, userRepo, nil, logger)
-	res, err := svc.ConfirmTransaction(context.Background(), transactionID)
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	res, err := svc.ConfirmTransaction(systemCtx(), transactionID)
 
 	assert.Nil(t, res)
 	assert.Error(t, err)
-	assert.Equal(t, "transaction expired or already completed", err.Error())
+	assert.EqualError(t, err, "transaction has expired")
+	assert.Equal(t, model.KindExpired, model.KindOf(err))
 }
 
 func TestConfirmTransaction_InvalidStatus(t *testing.T) {
@@ -142,12 +269,13 @@ func TestConfirmTransaction_InvalidStatus(t *testing.T) {
 
 	txnRepo.On("GetTransactionByID", transactionID).Return(txn, nil)
 
-	s := service.NewWalletService(txnRepo, userRepo, nil, logger)
-	res, err := s.ConfirmTransaction(context.Background(), transactionID)
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	res, err := s.ConfirmTransaction(systemCtx(), transactionID)
 
 	assert.Nil(t, res)
 	assert.Error(t, err)
-	assert.Equal(t, "transaction expired or already completed", err.Error())
+	assert.EqualError(t, err, "transaction is already completed")
+	assert.Equal(t, model.KindAlreadyCompleted, model.KindOf(err))
 }
 
 func TestConfirmTransaction_NotFound(t *testing.T) {
@@ -157,12 +285,326 @@ func TestConfirmTransaction_NotFound(t *testing.T) {
 
 	transactionID := uuid.New().String()
 
-	txnRepo.On("GetTransactionByID", transactionID).Return((*model.Transaction)(nil), errors.New("not found"))
+	txnRepo.On("GetTransactionByID", transactionID).Return((*model.Transaction)(nil), gorm.ErrRecordNotFound)
 
-	s := service.NewWalletService(txnRepo, userRepo, nil, logger)
-	res, err := s.ConfirmTransaction(context.Background(), transactionID)
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	res, err := s.ConfirmTransaction(systemCtx(), transactionID)
 
 	assert.Nil(t, res)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "transaction not found")
+	assert.Equal(t, model.KindNotFound, model.KindOf(err))
+}
+
+func TestVerifyTransaction_OtherCustomerForbidden(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	owner := uint(2)
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "alice", Roles: []string{model.RoleCustomer}, UserID: &owner,
+	})
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := svc.VerifyTransaction(ctx, 1, 100, "credit_card")
+
+	assert.ErrorIs(t, err, model.ErrForbidden)
+	userRepo.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+}
+
+func TestVerifyTransaction_NoCallerRefused(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := svc.VerifyTransaction(context.Background(), 1, 100, "credit_card")
+
+	assert.ErrorIs(t, err, model.ErrUnauthenticated)
+	userRepo.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	txnRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything)
+}
+
+func TestConfirmTransaction_OtherCustomerForbidden(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	transactionID := uuid.New().String()
+	txnRepo.On("GetTransactionByID", transactionID).Return(&model.Transaction{
+		TransactionID: transactionID,
+		UserID:        1,
+		Amount:        100.0,
+		Status:        "verified",
+		ExpiresAt:     time.Now().Add(10 * time.Minute),
+	}, nil)
+
+	owner := uint(2)
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "alice", Roles: []string{model.RoleCustomer}, UserID: &owner,
+	})
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := svc.ConfirmTransaction(ctx, transactionID)
+
+	assert.ErrorIs(t, err, model.ErrForbidden)
+	txnRepo.AssertNotCalled(t, "CompleteTransaction", mock.Anything)
+	userRepo.AssertNotCalled(t, "UpdateUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestConfirmTransaction_AdminOnBehalfIsAudited(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	auditRepo := new(mocks.AuditRepoMock)
+	logger := setupLogger()
+
+	transactionID := uuid.New().String()
+	txn := &model.Transaction{
+		TransactionID: transactionID,
+		UserID:        1,
+		Amount:        100.0,
+		Status:        "verified",
+		ExpiresAt:     time.Now().Add(10 * time.Minute),
+	}
+
+	txnRepo.On("GetTransactionByID", transactionID).Return(txn, nil)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletActive}, nil)
+	txnRepo.On("CompleteTransaction", transactionID).Return(nil)
+	userRepo.On("UpdateUserBalance", txn.UserID, txn.Amount).Return(nil)
+	auditRepo.On("CreateAuditLog", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		return entry.Actor == "ops" && entry.Action == "topup.confirmed_on_behalf" && entry.EntityID == transactionID
+	})).Return(nil)
+
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "ops", Roles: []string{model.RoleAdmin},
+	})
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger, service.WithAuditRepository(auditRepo))
+	res, err := svc.ConfirmTransaction(ctx, transactionID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", res.Status)
+	auditRepo.AssertExpectations(t)
+}
+
+func stepUpFixture(amount float64) (*mocks.TransactionRepoMock, *mocks.UserRepoMock, string, context.Context) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+
+	transactionID := uuid.New().String()
+	txnRepo.On("GetTransactionByID", transactionID).Return(&model.Transaction{
+		TransactionID: transactionID,
+		UserID:        1,
+		Amount:        amount,
+		Status:        "verified",
+		ExpiresAt:     time.Now().Add(10 * time.Minute),
+	}, nil)
+	userRepo.On("GetUserByID", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletActive, Phone: "0812345678"}, nil)
+
+	owner := uint(1)
+	ctx := model.WithPrincipal(context.Background(), &model.Principal{
+		Subject: "alice", Roles: []string{model.RoleCustomer}, UserID: &owner,
+	})
+	return txnRepo, userRepo, transactionID, ctx
+}
+
+func TestConfirmTransaction_StepUpChallengeThenCode(t *testing.T) {
+	txnRepo, userRepo, transactionID, ctx := stepUpFixture(50000)
+	redisMock := new(mocks.RedisMock)
+	sender := new(mocks.OTPSenderMock)
+	logger := setupLogger()
+
+	redisMock.On("Get", mock.Anything, "txn:"+transactionID).Return("", redis.Nil)
+
+	var sentCode, storedHash string
+	redisMock.On("Incr", mock.Anything, "otp:sends:"+transactionID).Return(1, nil)
+	redisMock.On("Expire", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	redisMock.On("Set", mock.Anything, "otp:"+transactionID, mock.Anything, service.OTPTTL).
+		Run(func(args mock.Arguments) { storedHash = args.String(2) }).Return(nil)
+	redisMock.On("Del", mock.Anything, mock.Anything).Return(nil)
+	sender.On("SendOTP", mock.Anything, "sms", "0812345678", mock.Anything).
+		Run(func(args mock.Arguments) { sentCode = args.String(3) }).Return(nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger, service.WithStepUp(10000, sender))
+	_, err := svc.ConfirmTransaction(ctx, transactionID)
+
+	var challenge *model.ChallengeRequiredError
+	assert.ErrorAs(t, err, &challenge)
+	assert.Equal(t, "sms", challenge.Channel)
+	assert.Equal(t, "081****678", challenge.Destination)
+	assert.Len(t, sentCode, 6)
+	assert.NotContains(t, storedHash, sentCode)
+	txnRepo.AssertNotCalled(t, "CompleteTransaction", mock.Anything)
+
+	redisMock.On("Incr", mock.Anything, "otp:attempts:"+transactionID).Return(1, nil)
+	redisMock.On("Get", mock.Anything, "otp:"+transactionID).Return(storedHash, nil)
+	txnRepo.On("CompleteTransaction", transactionID).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(1), 50000.0).Return(nil)
+
+	res, err := svc.ConfirmWithCode(ctx, transactionID, sentCode)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", res.Status)
+}
+
+func TestConfirmWithCode_WrongCode(t *testing.T) {
+	txnRepo, userRepo, transactionID, ctx := stepUpFixture(50000)
+	redisMock := new(mocks.RedisMock)
+	logger := setupLogger()
+
+	redisMock.On("Get", mock.Anything, "txn:"+transactionID).Return("", redis.Nil)
+
+	redisMock.On("Incr", mock.Anything, "otp:attempts:"+transactionID).Return(2, nil)
+	redisMock.On("Get", mock.Anything, "otp:"+transactionID).Return("not-the-hash", nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger, service.WithStepUp(10000, new(mocks.OTPSenderMock)))
+	_, err := svc.ConfirmWithCode(ctx, transactionID, "123456")
+
+	assert.ErrorIs(t, err, model.ErrInvalidOTP)
+	txnRepo.AssertNotCalled(t, "CompleteTransaction", mock.Anything)
+}
+
+func TestConfirmWithCode_AttemptsExceeded(t *testing.T) {
+	txnRepo, userRepo, transactionID, ctx := stepUpFixture(50000)
+	redisMock := new(mocks.RedisMock)
+	logger := setupLogger()
+
+	redisMock.On("Get", mock.Anything, "txn:"+transactionID).Return("", redis.Nil)
+
+	redisMock.On("Incr", mock.Anything, "otp:attempts:"+transactionID).Return(service.MaxOTPAttempts+1, nil)
+	redisMock.On("Del", mock.Anything, []string{"otp:" + transactionID}).Return(nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger, service.WithStepUp(10000, new(mocks.OTPSenderMock)))
+	_, err := svc.ConfirmWithCode(ctx, transactionID, "123456")
+
+	assert.ErrorIs(t, err, model.ErrOTPAttemptsExceeded)
+	redisMock.AssertNotCalled(t, "Get", mock.Anything, "otp:"+transactionID)
+}
+
+func TestConfirmTransaction_StepUpResendLimited(t *testing.T) {
+	txnRepo, userRepo, transactionID, ctx := stepUpFixture(50000)
+	redisMock := new(mocks.RedisMock)
+	sender := new(mocks.OTPSenderMock)
+	logger := setupLogger()
+
+	redisMock.On("Get", mock.Anything, "txn:"+transactionID).Return("", redis.Nil)
+
+	redisMock.On("Incr", mock.Anything, "otp:sends:"+transactionID).Return(service.MaxOTPSends+1, nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger, service.WithStepUp(10000, sender))
+	_, err := svc.ConfirmTransaction(ctx, transactionID)
+
+	assert.ErrorIs(t, err, model.ErrOTPRateLimited)
+	sender.AssertNotCalled(t, "SendOTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfirmTransaction_BelowStepUpThreshold(t *testing.T) {
+	txnRepo, userRepo, transactionID, ctx := stepUpFixture(500)
+	logger := setupLogger()
+
+	txnRepo.On("CompleteTransaction", transactionID).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(1), 500.0).Return(nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger, service.WithStepUp(10000, new(mocks.OTPSenderMock)))
+	res, err := svc.ConfirmTransaction(ctx, transactionID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", res.Status)
+}
+
+func TestConfirmTransaction_StepUpSkippedForSystemJobs(t *testing.T) {
+	txnRepo, userRepo, transactionID, _ := stepUpFixture(50000)
+	sender := new(mocks.OTPSenderMock)
+	logger := setupLogger()
+
+	txnRepo.On("CompleteTransaction", transactionID).Return(nil)
+	userRepo.On("UpdateUserBalance", uint(1), 50000.0).Return(nil)
+
+	svc := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger, service.WithStepUp(10000, sender))
+	res, err := svc.ConfirmTransaction(systemCtx(), transactionID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", res.Status)
+	sender.AssertNotCalled(t, "SendOTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExtendTransaction_Success(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	redisMock := new(mocks.RedisMock)
+	logger := setupLogger()
+
+	transactionID := uuid.New().String()
+	txn := &model.Transaction{
+		TransactionID: transactionID,
+		UserID:        1,
+		Amount:        100.0,
+		Status:        "verified",
+		CreatedAt:     time.Now().Add(-10 * time.Minute),
+		ExpiresAt:     time.Now().Add(5 * time.Minute),
+	}
+
+	txnRepo.On("GetTransactionByID", transactionID).Return(txn, nil)
+	txnRepo.On("ExtendTransaction", transactionID, mock.Anything).Return(nil)
+	redisMock.On("Del", mock.Anything, []string{"txn:" + transactionID}).Return(nil)
+
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, redisMock, logger)
+	res, err := s.ExtendTransaction(systemCtx(), transactionID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Extensions)
+	assert.WithinDuration(t, time.Now().Add(service.VerifyTTL), res.ExpiresAt, time.Second)
+	redisMock.AssertExpectations(t)
+}
+
+func TestExtendTransaction_CappedByMaxLifetime(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	createdAt := time.Now().Add(-25 * time.Minute)
+	transactionID := uuid.New().String()
+	txn := &model.Transaction{
+		TransactionID: transactionID,
+		Status:        "verified",
+		CreatedAt:     createdAt,
+		ExpiresAt:     time.Now().Add(2 * time.Minute),
+		Extensions:    1,
+	}
+
+	txnRepo.On("GetTransactionByID", transactionID).Return(txn, nil)
+	txnRepo.On("ExtendTransaction", transactionID, createdAt.Add(30*time.Minute)).Return(nil)
+
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger, service.WithMaxTransactionLifetime(30*time.Minute))
+	res, err := s.ExtendTransaction(systemCtx(), transactionID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt.Add(30*time.Minute), res.ExpiresAt)
+	assert.Equal(t, 2, res.Extensions)
+
+	txn.ExpiresAt = createdAt.Add(30 * time.Minute)
+	_, err = s.ExtendTransaction(systemCtx(), transactionID)
+	assert.EqualError(t, err, "transaction has reached its maximum lifetime")
+}
+
+func TestExtendTransaction_AlreadyCompleted(t *testing.T) {
+	txnRepo := new(mocks.TransactionRepoMock)
+	userRepo := new(mocks.UserRepoMock)
+	logger := setupLogger()
+
+	transactionID := uuid.New().String()
+	txnRepo.On("GetTransactionByID", transactionID).Return(&model.Transaction{
+		TransactionID: transactionID,
+		Status:        "completed",
+		ExpiresAt:     time.Now().Add(5 * time.Minute),
+	}, nil)
+
+	s := service.NewWalletService(walletTx(txnRepo, userRepo), txnRepo, userRepo, nil, logger)
+	_, err := s.ExtendTransaction(systemCtx(), transactionID)
+
+	assert.EqualError(t, err, "transaction is already completed")
+	assert.Equal(t, model.KindAlreadyCompleted, model.KindOf(err))
+	txnRepo.AssertNotCalled(t, "ExtendTransaction", mock.Anything, mock.Anything)
 }
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+type WalletStatusService struct {
+	txManager  model.TxManager
+	statusRepo model.WalletStatusRepository
+	logger     logs.Logger
+}
+
+func NewWalletStatusService(
+	txManager model.TxManager,
+	statusRepo model.WalletStatusRepository,
+	logger logs.Logger,
+) model.WalletStatusService {
+	return &WalletStatusService{
+		txManager:  txManager,
+		statusRepo: statusRepo,
+		logger:     logger,
+	}
+}
+
+// ChangeStatus moves a wallet to status and records the change. Closed is
+// final, and a wallet can only be closed once its balance has been paid out
+// and no holds remain.
+func (s *WalletStatusService) ChangeStatus(ctx context.Context, actor string, userID uint, status, reason string) (*model.User, error) {
+	if actor == "" {
+		return nil, model.Invalid("actor identity is required")
+	}
+	switch status {
+	case model.WalletActive, model.WalletFrozen, model.WalletSuspended, model.WalletClosed:
+	default:
+		return nil, model.Invalid("status must be active, frozen, suspended or closed")
+	}
+	if reason == "" {
+		return nil, model.Invalid("reason is required")
+	}
+
+	var user *model.User
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		var err error
+		user, err = repos.Users.GetUserByIDForUpdate(userID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		from := user.Status
+		if from == "" {
+			from = model.WalletActive
+		}
+		if from == status {
+			return model.Conflict("wallet is already " + status)
+		}
+		if from == model.WalletClosed {
+			return model.Conflict("closed wallets cannot be reopened")
+		}
+
+		if status == model.WalletClosed {
+			held, err := repos.Holds.SumActiveHolds(userID)
+			if err != nil {
+				return err
+			}
+			if user.Balance != 0 || held != 0 {
+				return model.Conflict("wallet balance must be zero before closing; withdraw the remaining balance first")
+			}
+		}
+
+		if err := repos.Users.UpdateUserStatus(userID, user.Status, status); err != nil {
+			return err
+		}
+		user.Status = status
+		return repos.StatusChanges.CreateStatusChange(&model.WalletStatusChange{
+			ChangeID:   uuid.New().String(),
+			UserID:     userID,
+			FromStatus: from,
+			ToStatus:   status,
+			Reason:     reason,
+			Actor:      actor,
+			CreatedAt:  time.Now(),
+		})
+	})
+	if err != nil {
+		s.logger.Warnf("change status of user_id=%d to %s by %s failed: %v", userID, status, actor, err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("wallet status changed: user_id=%d status=%s by %s", userID, status, actor)
+	return user, nil
+}
+
+func (s *WalletStatusService) GetStatusHistory(ctx context.Context, userID uint) ([]model.WalletStatusChange, error) {
+	return s.statusRepo.ListStatusChanges(userID)
+}
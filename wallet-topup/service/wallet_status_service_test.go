@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupWalletStatusService() (*mocks.UserRepoMock, *mocks.HoldRepoMock, *mocks.WalletStatusRepoMock, model.WalletStatusService) {
+	userRepo := new(mocks.UserRepoMock)
+	holdRepo := new(mocks.HoldRepoMock)
+	statusRepo := new(mocks.WalletStatusRepoMock)
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:         userRepo,
+		Holds:         holdRepo,
+		StatusChanges: statusRepo,
+	}}
+	return userRepo, holdRepo, statusRepo, service.NewWalletStatusService(txManager, statusRepo, setupLogger())
+}
+
+func TestChangeStatus_Freeze(t *testing.T) {
+	userRepo, _, statusRepo, s := setupWalletStatusService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 500, Status: model.WalletActive}, nil)
+	userRepo.On("UpdateUserStatus", uint(1), model.WalletActive, model.WalletFrozen).Return(nil)
+	statusRepo.On("CreateStatusChange", mock.MatchedBy(func(c *model.WalletStatusChange) bool {
+		return c.FromStatus == model.WalletActive && c.ToStatus == model.WalletFrozen && c.Actor == "alice" && c.Reason == "case-77"
+	})).Return(nil)
+
+	user, err := s.ChangeStatus(context.Background(), "alice", 1, model.WalletFrozen, "case-77")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.WalletFrozen, user.Status)
+	statusRepo.AssertExpectations(t)
+}
+
+func TestChangeStatus_CloseRequiresZeroBalance(t *testing.T) {
+	userRepo, holdRepo, _, s := setupWalletStatusService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 20, Status: model.WalletActive}, nil)
+	holdRepo.On("SumActiveHolds", uint(1)).Return(0.0, nil)
+
+	_, err := s.ChangeStatus(context.Background(), "alice", 1, model.WalletClosed, "customer request")
+
+	assert.EqualError(t, err, "wallet balance must be zero before closing; withdraw the remaining balance first")
+	userRepo.AssertNotCalled(t, "UpdateUserStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeStatus_ClosedIsFinal(t *testing.T) {
+	userRepo, _, _, s := setupWalletStatusService()
+	userRepo.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Status: model.WalletClosed}, nil)
+
+	_, err := s.ChangeStatus(context.Background(), "alice", 1, model.WalletActive, "reopen")
+
+	assert.EqualError(t, err, "closed wallets cannot be reopened")
+}
+
+func TestChangeStatus_RequiresReason(t *testing.T) {
+	_, _, _, s := setupWalletStatusService()
+
+	_, err := s.ChangeStatus(context.Background(), "alice", 1, model.WalletFrozen, "")
+
+	assert.EqualError(t, err, "reason is required")
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"time"
+	"wallet-topup/logs"
+	"wallet-topup/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinWithdrawalAmount  = 100.00
+	MaxWithdrawalAmount  = 50000.00
+	DailyWithdrawalLimit = 100000.00
+)
+
+type WithdrawalService struct {
+	txManager      model.TxManager
+	withdrawalRepo model.WithdrawalRepository
+	payout         model.PayoutProvider
+	logger         logs.Logger
+}
+
+func NewWithdrawalService(
+	txManager model.TxManager,
+	withdrawalRepo model.WithdrawalRepository,
+	payout model.PayoutProvider,
+	logger logs.Logger,
+) model.WithdrawalService {
+	return &WithdrawalService{
+		txManager:      txManager,
+		withdrawalRepo: withdrawalRepo,
+		payout:         payout,
+		logger:         logger,
+	}
+}
+
+// RequestWithdrawal records the withdrawal, puts the amount on hold and submits
+// the payout. If the provider rejects the submission the hold is released and
+// the withdrawal is returned in the failed state.
+func (s *WithdrawalService) RequestWithdrawal(ctx context.Context, userID uint, amount float64, bankAccount string) (*model.Withdrawal, error) {
+	if _, err := authorizeWallet(ctx, s.logger, userID, "withdraw"); err != nil {
+		return nil, err
+	}
+	if bankAccount == "" {
+		return nil, model.Invalid("bank account is required")
+	}
+	if amount < MinWithdrawalAmount {
+		s.logger.Warnf("withdrawal amount %.2f below minimum for user_id=%d", amount, userID)
+		return nil, model.Invalid("amount is below minimum allowed")
+	}
+	if amount > MaxWithdrawalAmount {
+		s.logger.Warnf("withdrawal amount %.2f exceeds limit for user_id=%d", amount, userID)
+		return nil, model.LimitExceeded("amount exceeds maximum allowed")
+	}
+
+	now := time.Now()
+	withdrawal := &model.Withdrawal{
+		WithdrawalID: uuid.New().String(),
+		UserID:       userID,
+		Amount:       amount,
+		BankAccount:  bankAccount,
+		Status:       model.WithdrawalRequested,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		user, err := repos.Users.GetUserByIDForUpdate(userID)
+		if err != nil {
+			return lookupError(err, "user")
+		}
+		if err := user.CheckActive(); err != nil {
+			return err
+		}
+		if tier := model.TierFor(user.KYCTier); !tier.Withdrawals {
+			return model.LimitExceeded("withdrawals are not available for kyc tier " + tier.Name)
+		}
+
+		requested, err := repos.Withdrawals.SumActiveSince(userID, startOfDay(now))
+		if err != nil {
+			return err
+		}
+		if requested+amount > DailyWithdrawalLimit {
+			return model.LimitExceeded("daily withdrawal limit exceeded")
+		}
+
+		if err := repos.Withdrawals.CreateWithdrawal(withdrawal); err != nil {
+			return err
+		}
+		hold, err := placeHold(repos, userID, nil, amount, model.HoldReasonWithdrawal, nil)
+		if err != nil {
+			return err
+		}
+
+		withdrawal.HoldID = hold.HoldID
+		withdrawal.Status = model.WithdrawalHeld
+		return repos.Withdrawals.UpdateWithdrawal(withdrawal, model.WithdrawalRequested)
+	})
+	if err != nil {
+		s.logger.Warnf("withdrawal for user_id=%d failed: %v", userID, err)
+		return nil, storeError(err)
+	}
+
+	ref, err := s.payout.SubmitPayout(ctx, withdrawal)
+	if err != nil {
+		s.logger.Error("payout submission error:", err)
+		if err := s.fail(withdrawal, model.WithdrawalHeld, "payout submission failed"); err != nil {
+			return nil, err
+		}
+		return withdrawal, nil
+	}
+
+	withdrawal.Status = model.WithdrawalSubmitted
+	withdrawal.PayoutReference = ref
+	withdrawal.UpdatedAt = time.Now()
+	if err := s.withdrawalRepo.UpdateWithdrawal(withdrawal, model.WithdrawalHeld); err != nil {
+		s.logger.Error("update withdrawal error:", err)
+		return nil, storeError(err)
+	}
+
+	s.logger.Infof("withdrawal submitted: %s", withdrawal.WithdrawalID)
+	return withdrawal, nil
+}
+
+func (s *WithdrawalService) GetWithdrawal(ctx context.Context, withdrawalID string) (*model.Withdrawal, error) {
+	withdrawal, err := s.withdrawalRepo.GetWithdrawalByID(withdrawalID)
+	if err != nil {
+		return nil, lookupError(err, "withdrawal")
+	}
+	if _, err := authorizeWallet(ctx, s.logger, withdrawal.UserID, "read withdrawal"); err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// RefreshWithdrawal asks the payout provider for the outcome of a submitted
+// withdrawal and settles it. Withdrawals in any other state are returned as is.
+func (s *WithdrawalService) RefreshWithdrawal(ctx context.Context, withdrawalID string) (*model.Withdrawal, error) {
+	withdrawal, err := s.GetWithdrawal(ctx, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawal.Status != model.WithdrawalSubmitted {
+		return withdrawal, nil
+	}
+
+	status, reason, err := s.payout.GetPayoutStatus(ctx, withdrawal.PayoutReference)
+	if err != nil {
+		s.logger.Error("payout status error:", err)
+		return nil, err
+	}
+
+	switch status {
+	case model.PayoutPaid:
+		if err := s.settle(withdrawal); err != nil {
+			return nil, err
+		}
+	case model.PayoutFailed:
+		if err := s.fail(withdrawal, model.WithdrawalSubmitted, reason); err != nil {
+			return nil, err
+		}
+	}
+	return withdrawal, nil
+}
+
+// settle marks the withdrawal as paid and captures its hold, debiting the
+// wallet.
+func (s *WithdrawalService) settle(withdrawal *model.Withdrawal) error {
+	withdrawal.Status = model.WithdrawalPaid
+	withdrawal.UpdatedAt = time.Now()
+
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Withdrawals.UpdateWithdrawal(withdrawal, model.WithdrawalSubmitted); err != nil {
+			return err
+		}
+		hold, err := repos.Holds.GetHoldByID(withdrawal.HoldID)
+		if err != nil {
+			return err
+		}
+		return captureHold(repos, hold, "withdrawal", withdrawal.WithdrawalID)
+	})
+	if err != nil {
+		s.logger.Error("capture withdrawal hold error:", err)
+		return storeError(err)
+	}
+
+	s.logger.Infof("withdrawal paid: %s", withdrawal.WithdrawalID)
+	return nil
+}
+
+// fail marks the withdrawal as failed and releases its hold. The status guard
+// makes sure the hold is released only once.
+func (s *WithdrawalService) fail(withdrawal *model.Withdrawal, fromStatus, reason string) error {
+	withdrawal.Status = model.WithdrawalFailed
+	withdrawal.FailureReason = reason
+	withdrawal.UpdatedAt = time.Now()
+
+	err := s.txManager.WithinTransaction(func(repos model.TxRepositories) error {
+		if err := repos.Withdrawals.UpdateWithdrawal(withdrawal, fromStatus); err != nil {
+			return err
+		}
+		return repos.Holds.UpdateHoldStatus(withdrawal.HoldID, model.HoldActive, model.HoldReleased)
+	})
+	if err != nil {
+		s.logger.Error("release withdrawal hold error:", err)
+		return storeError(err)
+	}
+
+	s.logger.Warnf("withdrawal failed: %s (%s)", withdrawal.WithdrawalID, reason)
+	return nil
+}
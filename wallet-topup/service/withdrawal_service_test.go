@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"wallet-topup/mocks"
+	"wallet-topup/model"
+	"wallet-topup/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type withdrawalMocks struct {
+	users       *mocks.UserRepoMock
+	withdrawals *mocks.WithdrawalRepoMock
+	ledger      *mocks.LedgerRepoMock
+	holds       *mocks.HoldRepoMock
+	payout      *mocks.PayoutProviderMock
+}
+
+func setupWithdrawalService() (*withdrawalMocks, model.WithdrawalService) {
+	m := &withdrawalMocks{
+		users:       new(mocks.UserRepoMock),
+		withdrawals: new(mocks.WithdrawalRepoMock),
+		ledger:      new(mocks.LedgerRepoMock),
+		holds:       new(mocks.HoldRepoMock),
+		payout:      new(mocks.PayoutProviderMock),
+	}
+	txManager := &mocks.TxManagerMock{Repos: model.TxRepositories{
+		Users:       m.users,
+		Ledger:      m.ledger,
+		Withdrawals: m.withdrawals,
+		Holds:       m.holds,
+	}}
+	return m, service.NewWithdrawalService(txManager, m.withdrawals, m.payout, setupLogger())
+}
+
+func (m *withdrawalMocks) expectHold(userID uint, amount float64) {
+	m.users.On("GetUserByIDForUpdate", userID).Return(&model.User{UserID: userID, Balance: 1000, KYCTier: model.KYCVerified}, nil)
+	m.withdrawals.On("SumActiveSince", userID, mock.Anything).Return(0.0, nil)
+	m.withdrawals.On("CreateWithdrawal", mock.Anything).Return(nil)
+	m.holds.On("SumActiveHolds", userID).Return(0.0, nil)
+	m.holds.On("CreateHold", mock.Anything).Return(nil)
+	m.withdrawals.On("UpdateWithdrawal", mock.Anything, model.WithdrawalRequested).Return(nil)
+}
+
+func TestRequestWithdrawal_Submitted(t *testing.T) {
+	m, s := setupWithdrawalService()
+	m.expectHold(1, 500.0)
+	m.payout.On("SubmitPayout", mock.Anything, mock.Anything).Return("payout_1", nil)
+	m.withdrawals.On("UpdateWithdrawal", mock.Anything, model.WithdrawalHeld).Return(nil)
+
+	w, err := s.RequestWithdrawal(systemCtx(), 1, 500.0, "1234567890")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalSubmitted, w.Status)
+	assert.Equal(t, "payout_1", w.PayoutReference)
+	assert.NotEmpty(t, w.HoldID)
+	m.users.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestRequestWithdrawal_SubmitFailureReleasesHold(t *testing.T) {
+	m, s := setupWithdrawalService()
+	m.expectHold(1, 500.0)
+	m.payout.On("SubmitPayout", mock.Anything, mock.Anything).Return("", errors.New("gateway down"))
+	m.withdrawals.On("UpdateWithdrawal", mock.Anything, model.WithdrawalHeld).Return(nil)
+	m.holds.On("UpdateHoldStatus", mock.Anything, model.HoldActive, model.HoldReleased).Return(nil)
+
+	w, err := s.RequestWithdrawal(systemCtx(), 1, 500.0, "1234567890")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalFailed, w.Status)
+	m.holds.AssertCalled(t, "UpdateHoldStatus", w.HoldID, model.HoldActive, model.HoldReleased)
+}
+
+func TestRequestWithdrawal_InsufficientFunds(t *testing.T) {
+	m, s := setupWithdrawalService()
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 600, KYCTier: model.KYCVerified}, nil)
+	m.withdrawals.On("SumActiveSince", uint(1), mock.Anything).Return(0.0, nil)
+	m.withdrawals.On("CreateWithdrawal", mock.Anything).Return(nil)
+	m.holds.On("SumActiveHolds", uint(1)).Return(200.0, nil)
+
+	_, err := s.RequestWithdrawal(systemCtx(), 1, 500.0, "1234567890")
+
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	m.payout.AssertNotCalled(t, "SubmitPayout", mock.Anything, mock.Anything)
+}
+
+func TestRequestWithdrawal_InvalidAmount(t *testing.T) {
+	_, s := setupWithdrawalService()
+
+	_, err := s.RequestWithdrawal(systemCtx(), 1, 10.0, "1234567890")
+	assert.EqualError(t, err, "amount is below minimum allowed")
+
+	_, err = s.RequestWithdrawal(systemCtx(), 1, service.MaxWithdrawalAmount+1, "1234567890")
+	assert.EqualError(t, err, "amount exceeds maximum allowed")
+}
+
+func TestRefreshWithdrawal_Paid(t *testing.T) {
+	m, s := setupWithdrawalService()
+	m.withdrawals.On("GetWithdrawalByID", "w1").Return(&model.Withdrawal{
+		WithdrawalID: "w1", UserID: 1, Amount: 500, Status: model.WithdrawalSubmitted, HoldID: "h1", PayoutReference: "payout_1",
+	}, nil)
+	m.payout.On("GetPayoutStatus", mock.Anything, "payout_1").Return(model.PayoutPaid, "", nil)
+	m.withdrawals.On("UpdateWithdrawal", mock.Anything, model.WithdrawalSubmitted).Return(nil)
+	m.holds.On("GetHoldByID", "h1").Return(&model.Hold{HoldID: "h1", UserID: 1, Amount: 500, Status: model.HoldActive}, nil)
+	m.holds.On("UpdateHoldStatus", "h1", model.HoldActive, model.HoldCaptured).Return(nil)
+	m.users.On("DebitUserBalance", uint(1), 500.0).Return(nil)
+	m.ledger.On("CreateEntries", mock.Anything).Return(nil)
+
+	w, err := s.RefreshWithdrawal(systemCtx(), "w1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalPaid, w.Status)
+	m.users.AssertCalled(t, "DebitUserBalance", uint(1), 500.0)
+}
+
+func TestRefreshWithdrawal_FailedReleasesHold(t *testing.T) {
+	m, s := setupWithdrawalService()
+	m.withdrawals.On("GetWithdrawalByID", "w1").Return(&model.Withdrawal{
+		WithdrawalID: "w1", UserID: 1, Amount: 500, Status: model.WithdrawalSubmitted, HoldID: "h1", PayoutReference: "payout_1",
+	}, nil)
+	m.payout.On("GetPayoutStatus", mock.Anything, "payout_1").Return(model.PayoutFailed, "bank account closed", nil)
+	m.withdrawals.On("UpdateWithdrawal", mock.Anything, model.WithdrawalSubmitted).Return(nil)
+	m.holds.On("UpdateHoldStatus", "h1", model.HoldActive, model.HoldReleased).Return(nil)
+
+	w, err := s.RefreshWithdrawal(systemCtx(), "w1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.WithdrawalFailed, w.Status)
+	assert.Equal(t, "bank account closed", w.FailureReason)
+	m.users.AssertNotCalled(t, "DebitUserBalance", mock.Anything, mock.Anything)
+}
+
+func TestRequestWithdrawal_BasicTierNotAllowed(t *testing.T) {
+	m, s := setupWithdrawalService()
+	m.users.On("GetUserByIDForUpdate", uint(1)).Return(&model.User{UserID: 1, Balance: 600, KYCTier: model.KYCBasic}, nil)
+
+	_, err := s.RequestWithdrawal(systemCtx(), 1, 500.0, "1234567890")
+
+	assert.EqualError(t, err, "withdrawals are not available for kyc tier basic")
+	m.withdrawals.AssertNotCalled(t, "CreateWithdrawal", mock.Anything)
+}
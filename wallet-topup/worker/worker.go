@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls fn once per interval until ctx is cancelled. Runs never overlap:
+// a slow run delays the next tick instead of stacking up.
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}